@@ -0,0 +1,199 @@
+package goqr
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"math"
+)
+
+//Append вывод структурированного объединения
+type Append byte
+
+const (
+	//AppendNone без объединения, данные больше одного qr возвращают ошибку
+	AppendNone Append = iota
+	//AppendFiles каждый qr в свой файл с номером: qr-1.png, qr-2.png и так далее
+	AppendFiles
+	//AppendSheet все qr на одном листе по строкам
+	AppendSheet
+)
+
+const (
+	//Режим структурированного объединения
+	modeAppend = 0x3
+	//Длина заголовка: режим, номер, количество и четность
+	lenAppend = 20
+	//Наибольшее количество qr в объединении
+	maxAppend = 16
+)
+
+//EncodeAppend кодирует строку в матрицы qr без вывода изображения, LevelAuto означает LevelM.
+//Строка, которая не помещается в один qr, делится структурированным объединением
+//на qr одной версии, но не больше 16. Способ вывода opt.Append не учитывается
+func EncodeAppend(content string, opt Options) ([]*Code, error) {
+	opt.Append = AppendNone
+	code, err := Encode(content, opt)
+	if err != errOversize {
+		if err != nil {
+			return nil, err
+		}
+		return []*Code{code}, nil
+	}
+	table, _ := checkOptions(&opt)
+	return qrAppend(content, table, opt)
+}
+
+//Кодирование структурированного объединения в qr одной версии
+func qrAppend(content string, table levelTable, opt Options) ([]*Code, error) {
+	version, parts, err := splitAppend(content, opt, table.maxData)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]*Code, len(parts))
+	for i := range parts {
+		dataImg, mask := buildQR(parts[i], version, table, opt.Mask)
+		codes[i] = &Code{modules: dataImg, version: version, level: opt.Level, mask: Mask0 + Mask(mask), qr: true}
+	}
+	return codes, nil
+}
+
+//Разбиение строки на части одной наименьшей версии
+func splitAppend(content string, opt Options, maxData *[]int) (version int, parts [][]segment, err error) {
+	pos := make([]int, 0, len(content)+1)
+	for i := range content {
+		pos = append(pos, i)
+	}
+	pos = append(pos, len(content))
+
+	//Жадное заполнение qr версии v, nil если частей больше limit
+	pack := func(v, limit int) (parts [][]segment, err error) {
+		head := qrHeader(groupVersion(v))
+		for start := 0; start < len(pos)-1; {
+			if len(parts) == limit {
+				return nil, nil
+			}
+			//Поиск самой длинной части, которая помещается в qr
+			lo, hi := start, start+(*maxData)[v]/3+1
+			if hi > len(pos)-1 {
+				hi = len(pos) - 1
+			}
+			var part []segment
+			for lo < hi {
+				mid := (lo + hi + 1) / 2
+				s, l := splitCharset(content[pos[start]:pos[mid]], head, opt)
+				if s == nil {
+					return nil, errors.New("content not in charset")
+				}
+				if l+lenAppend <= (*maxData)[v] {
+					lo = mid
+				} else {
+					hi = mid - 1
+				}
+			}
+			if lo == start {
+				return nil, nil
+			}
+			part, _ = splitCharset(content[pos[start]:pos[lo]], head, opt)
+			parts = append(parts, part)
+			start = lo
+		}
+		return parts, nil
+	}
+
+	//Наименьшее количество частей в наибольшей версии, затем наименьшая версия для него
+	lo, hi := opt.versions()
+	if parts, err = pack(hi, maxAppend); err != nil {
+		return 0, nil, err
+	}
+	if parts == nil {
+		return 0, nil, errOversize
+	}
+	version = hi
+	for count := len(parts); lo < hi; {
+		mid := (lo + hi) / 2
+		p, _ := pack(mid, count)
+		if p != nil {
+			version, parts = mid, p
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	parity := appendParity(parts, opt.FNC1 != FNC1None)
+	for i := range parts {
+		head := segment{mode: modeAppend, value: i<<12 | (len(parts)-1)<<8 | parity}
+		parts[i] = append([]segment{head}, parts[i]...)
+	}
+	return
+}
+
+//Четность объединения: XOR всех байт данных в том виде, в котором они закодированы.
+//Байтовый режим берется в кодировке сегмента, кандзи в Shift JIS, экранирование FNC1 снимается
+func appendParity(parts [][]segment, fnc1 bool) (parity int) {
+	sjis := newSJISEncoder()
+	for _, part := range parts {
+		for _, seg := range part {
+			content := seg.content
+			switch seg.mode {
+			case modeKanji:
+				for _, r := range content {
+					code, _ := sjis.code(r)
+					parity ^= code>>8 ^ code&0xff
+				}
+				continue
+			case modeAlphanum:
+				if fnc1 {
+					content = fnc1Unescaper.Replace(content)
+				}
+			case modeNumeric, modeByte:
+			default:
+				continue
+			}
+			for i := 0; i < len(content); i++ {
+				parity ^= int(content[i])
+			}
+		}
+	}
+	return
+}
+
+//Размещение qr одного размера на листе по строкам
+func sheetQR(imgs []interface{}, light color.Color) interface{} {
+	cols := int(math.Ceil(math.Sqrt(float64(len(imgs)))))
+	rows := (len(imgs) + cols - 1) / cols
+
+	if first, ok := imgs[0].(*gif.GIF); ok {
+		size := first.Image[0].Rect.Dx()
+		sheet := &gif.GIF{
+			Delay:     first.Delay,
+			LoopCount: first.LoopCount,
+		}
+		for k := range first.Image {
+			frame := image.NewPaletted(image.Rect(0, 0, cols*size, rows*size), palette.Plan9)
+			draw.Draw(frame, frame.Rect, image.NewUniform(light), image.Point{}, draw.Src)
+			for i, img := range imgs {
+				at := image.Pt(i%cols*size, i/cols*size)
+				src := img.(*gif.GIF).Image[k]
+				draw.Draw(frame, src.Rect.Add(at), src, image.Point{}, draw.Src)
+			}
+			sheet.Image = append(sheet.Image, frame)
+		}
+		return sheet
+	}
+
+	size := imgs[0].(image.Image).Bounds().Dx()
+	sheet := image.NewNRGBA(image.Rect(0, 0, cols*size, rows*size))
+	draw.Draw(sheet, sheet.Rect, image.NewUniform(light), image.Point{}, draw.Src)
+	for i, img := range imgs {
+		at := image.Pt(i%cols*size, i/cols*size)
+		src := img.(image.Image)
+		draw.Draw(sheet, src.Bounds().Add(at), src, image.Point{}, draw.Src)
+	}
+	return sheet
+}
@@ -0,0 +1,128 @@
+package goqr
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+//Части объединения с заголовками: номер, количество и четность всей строки
+func TestSplitAppend(t *testing.T) {
+	content := strings.Repeat("HELLO WORLD 0123456789 qr-code ", 3)
+	maxData := levelTables[LevelM].maxData
+	version, parts, err := splitAppend(content, Options{Level: LevelM, MaxVersion: 2}, maxData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 || len(parts) != 4 {
+		t.Fatalf("version %d parts %d, want 2 4", version+1, len(parts))
+	}
+	parity := 0
+	for i := 0; i < len(content); i++ {
+		parity ^= int(content[i])
+	}
+
+	var joined strings.Builder
+	for i, part := range parts {
+		want := i<<12 | (len(parts)-1)<<8 | parity
+		if part[0].mode != modeAppend || part[0].value != want {
+			t.Fatalf("part %d header mode %d value %#x, want %d %#x", i, part[0].mode, part[0].value, modeAppend, want)
+		}
+		//Заголовок в начале последовательности: режим 0011, номер, количество - 1 и четность
+		data := addServicesData(part, qrHeader(groupVersion(version)), (*maxData)[version])
+		head := 0
+		for k := 0; k < lenAppend; k++ {
+			head = head<<1 | int(data.bit(k))
+		}
+		if head != modeAppend<<16|want {
+			t.Errorf("part %d header bits %#05x, want %#05x", i, head, modeAppend<<16|want)
+		}
+		length := 0
+		for _, seg := range part {
+			length += lenSegment(seg, qrHeader(groupVersion(version)))
+			joined.WriteString(seg.content)
+		}
+		if length > (*maxData)[version] {
+			t.Errorf("part %d length %d, want at most %d", i, length, (*maxData)[version])
+		}
+	}
+	if joined.String() != content {
+		t.Errorf("joined parts %q, want %q", joined.String(), content)
+	}
+}
+
+//Строка больше 16 qr наибольшей версии не кодируется
+func TestSplitAppendOversize(t *testing.T) {
+	content := strings.Repeat("a", 17*(*levelTables[LevelM].maxData)[0]/8)
+	if _, _, err := splitAppend(content, Options{Level: LevelM, MaxVersion: 1}, levelTables[LevelM].maxData); err != errOversize {
+		t.Fatalf("err %v, want %v", err, errOversize)
+	}
+}
+
+//EncodeAppend возвращает один qr без объединения или несколько qr одной версии
+func TestEncodeAppend(t *testing.T) {
+	codes, err := EncodeAppend("0123456789", Options{MaxVersion: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 1 {
+		t.Fatalf("codes %d, want 1", len(codes))
+	}
+	codes, err = EncodeAppend(strings.Repeat("HELLO WORLD 0123456789 qr-code ", 3), Options{Level: LevelM, MaxVersion: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 4 {
+		t.Fatalf("codes %d, want 4", len(codes))
+	}
+	for i, code := range codes {
+		if code.Version() != 2 || code.Level() != LevelM {
+			t.Errorf("code %d version %d level %d, want 2 %d", i, code.Version(), code.Level(), LevelM)
+		}
+	}
+	if _, err := Encode("0123456789", Options{Append: AppendSheet}); err == nil {
+		t.Error("Encode with append: want error")
+	}
+}
+
+//Четность считается по байтам данных в кодировке символа, а не по UTF-8 строки
+func TestSplitAppendParity(t *testing.T) {
+	xor := func(s string) (parity int) {
+		for i := 0; i < len(s); i++ {
+			parity ^= int(s[i])
+		}
+		return
+	}
+	cyrillic := strings.Repeat("Съешь же ещё этих мягких булок. ", 3)
+	cp1251, _ := charmap.Windows1251.NewEncoder().String(cyrillic)
+	kanji := strings.Repeat("日本語漢字", 6)
+	sjis, _ := japanese.ShiftJIS.NewEncoder().String(kanji)
+	gs1 := "0104601234567893" + "10ABC%123" + string(rune(groupSeparator)) + "21SERIAL%%42" + string(rune(groupSeparator)) + "17250101"
+	for _, tt := range []struct {
+		name    string
+		content string
+		opt     Options
+		parity  int
+	}{
+		{"windows-1251", cyrillic, Options{Charset: CharsetWindows1251}, xor(cp1251)},
+		{"kanji", kanji, Options{}, xor(sjis)},
+		{"fnc1", gs1, Options{FNC1: FNC1First}, xor(gs1)},
+	} {
+		tt.opt.Level = LevelM
+		tt.opt.MaxVersion = 1
+		_, parts, err := splitAppend(tt.content, tt.opt, levelTables[LevelM].maxData)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(parts) < 2 {
+			t.Fatalf("%s: parts %d, want at least 2", tt.name, len(parts))
+		}
+		for i, part := range parts {
+			if parity := part[0].value & 0xff; parity != tt.parity {
+				t.Errorf("%s: part %d parity %#02x, want %#02x", tt.name, i, parity, tt.parity)
+			}
+		}
+	}
+}
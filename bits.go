@@ -0,0 +1,64 @@
+package goqr
+
+//Последовательность бит, упакованных по восемь в байт старшим битом вперед
+type bitBuffer struct {
+	data []byte
+	//Количество записанных бит
+	length int
+}
+
+//Пустая последовательность с местом под size бит
+func newBitBuffer(size int) *bitBuffer {
+	return &bitBuffer{data: make([]byte, 0, (size+7)/8)}
+}
+
+//Последовательность из целых байт
+func bytesToBits(data []byte) *bitBuffer {
+	return &bitBuffer{data: data, length: len(data) * 8}
+}
+
+//Количество бит
+func (b *bitBuffer) len() int {
+	return b.length
+}
+
+//Дописывание n младших бит значения старшим битом вперед
+func (b *bitBuffer) put(value, n int) {
+	for n > 0 {
+		if b.length%8 == 0 {
+			b.data = append(b.data, 0)
+		}
+		//Сколько бит помещается в последний байт
+		free := 8 - b.length%8
+		k := free
+		if n < k {
+			k = n
+		}
+		bits := byte(value>>(n-k)) & (1<<k - 1)
+		b.data[len(b.data)-1] |= bits << (free - k)
+		b.length += k
+		n -= k
+	}
+}
+
+//Дописывание байт
+func (b *bitBuffer) putBytes(data []byte) {
+	if b.length%8 == 0 {
+		b.data = append(b.data, data...)
+		b.length += len(data) * 8
+		return
+	}
+	for _, v := range data {
+		b.put(int(v), 8)
+	}
+}
+
+//Бит на позиции i
+func (b *bitBuffer) bit(i int) byte {
+	return b.data[i/8] >> (7 - i%8) & 1
+}
+
+//Байты последовательности, неполный последний байт дополнен нулями
+func (b *bitBuffer) bytes() []byte {
+	return b.data
+}
@@ -0,0 +1,73 @@
+package goqr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+//Последовательность в виде строки из 0 и 1
+func bitString(b *bitBuffer) string {
+	var s strings.Builder
+	for i := 0; i < b.len(); i++ {
+		s.WriteByte('0' + b.bit(i))
+	}
+	return s.String()
+}
+
+//Запись значений разной длины с переходом через границы байт
+func TestBitBufferPut(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		puts  [][2]int
+		bits  string
+		bytes []byte
+	}{
+		{"aligned byte", [][2]int{{0xa5, 8}}, "10100101", []byte{0xa5}},
+		{"across boundary", [][2]int{{0x5, 3}, {0x3f, 6}}, "101111111", []byte{0xbf, 0x80}},
+		{"many small", [][2]int{{1, 1}, {0, 2}, {3, 2}, {2, 3}, {1, 2}}, "1001101001", []byte{0x9a, 0x40}},
+		{"n over 8", [][2]int{{0x1, 2}, {0x1abc, 13}}, "011101010111100", []byte{0x75, 0x78}},
+		{"n over 16 unaligned", [][2]int{{0, 5}, {0xc00000 | 100000, 24}}, "00000" + "110000011000011010100000", []byte{0x06, 0x0c, 0x35, 0x00}},
+		{"high bits ignored", [][2]int{{0xff0, 4}, {0x1ff, 4}}, "00001111", []byte{0x0f}},
+		{"zero length", [][2]int{{1, 0}, {1, 1}}, "1", []byte{0x80}},
+	} {
+		b := newBitBuffer(8)
+		for _, p := range tt.puts {
+			b.put(p[0], p[1])
+		}
+		if got := bitString(b); got != tt.bits {
+			t.Errorf("%s: bits %s, want %s", tt.name, got, tt.bits)
+		}
+		if !bytes.Equal(b.bytes(), tt.bytes) {
+			t.Errorf("%s: bytes %x, want %x", tt.name, b.bytes(), tt.bytes)
+		}
+	}
+}
+
+//Дописывание байт в выровненную и невыровненную последовательность
+func TestBitBufferPutBytes(t *testing.T) {
+	data := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	aligned := bytesToBits([]byte{0x01})
+	aligned.putBytes(data)
+	if aligned.len() != 40 || !bytes.Equal(aligned.bytes(), []byte{0x01, 0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("aligned: %d bits %x", aligned.len(), aligned.bytes())
+	}
+
+	for shift := 1; shift < 8; shift++ {
+		b := newBitBuffer(0)
+		b.put(0x7f, shift)
+		b.putBytes(data)
+		if b.len() != shift+32 {
+			t.Errorf("shift %d: %d bits, want %d", shift, b.len(), shift+32)
+		}
+		want := strings.Repeat("1", shift) + "11011110101011011011111011101111"
+		if got := bitString(b); got != want {
+			t.Errorf("shift %d: bits %s, want %s", shift, got, want)
+		}
+		//Хвост последнего байта дополнен нулями
+		if last := b.bytes()[len(b.bytes())-1]; last&(1<<(8-shift)-1) != 0 {
+			t.Errorf("shift %d: last byte %08b, want zero tail", shift, last)
+		}
+	}
+}
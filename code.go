@@ -0,0 +1,89 @@
+package goqr
+
+//Code матрица модулей символа qr, micro qr или rmqr
+type Code struct {
+	modules [][]byte
+	version int
+	level   Level
+	mask    Mask
+	//Символ qr, только на него кладется картинка
+	qr bool
+}
+
+//Pattern назначение модуля символа
+type Pattern byte
+
+const (
+	//PatternData модуль данных или коррекции
+	PatternData Pattern = iota
+	//PatternFinder поисковый узор с разделителем
+	PatternFinder
+	//PatternTiming полоса синхронизации
+	PatternTiming
+	//PatternFormat информация о формате
+	PatternFormat
+	//PatternVersion информация о версии
+	PatternVersion
+	//PatternAlignment выравнивающий узор
+	PatternAlignment
+	//PatternQuiet тихая зона вокруг символа
+	PatternQuiet
+)
+
+//Size сторона символа в модулях, для rmqr ширина
+func (c *Code) Size() int {
+	return c.Width()
+}
+
+//Width ширина символа в модулях
+func (c *Code) Width() int {
+	return len(c.modules[0])
+}
+
+//Height высота символа в модулях
+func (c *Code) Height() int {
+	return len(c.modules)
+}
+
+//Version номер версии: 1–40 для qr, 1–4 для M1–M4, 1–32 для R7x43–R17x139
+func (c *Code) Version() int {
+	return c.version + 1
+}
+
+//Level уровень коррекции ошибок
+func (c *Code) Level() Level {
+	return c.level
+}
+
+//Mask наложенная маска
+func (c *Code) Mask() Mask {
+	return c.mask
+}
+
+//Module темный ли модуль в столбце x и строке y, вне символа модули светлые
+func (c *Code) Module(x, y int) bool {
+	if y < 0 || y >= len(c.modules) || x < 0 || x >= len(c.modules[y]) {
+		return false
+	}
+	return c.modules[y][x]%2 == 1
+}
+
+//Pattern назначение модуля в столбце x и строке y
+func (c *Code) Pattern(x, y int) Pattern {
+	if y < 0 || y >= len(c.modules) || x < 0 || x >= len(c.modules[y]) {
+		return PatternQuiet
+	}
+	switch c.modules[y][x] {
+	case search0, search1:
+		return PatternFinder
+	case sync0, sync1:
+		return PatternTiming
+	case mask0, mask1:
+		return PatternFormat
+	case version0, version1:
+		return PatternVersion
+	case anchor0, anchor1:
+		return PatternAlignment
+	}
+	return PatternData
+}
@@ -0,0 +1,132 @@
+package goqr
+
+import "golang.org/x/text/encoding/charmap"
+
+//Charset кодировка байтового режима
+type Charset byte
+
+const (
+	//CharsetDefault байты UTF-8 без заголовка ECI
+	CharsetDefault Charset = iota
+	//CharsetAuto однобайтовая кодировка с самой короткой записью, иначе UTF-8
+	CharsetAuto
+	//CharsetUTF8 UTF-8, ECI 26
+	CharsetUTF8
+	//CharsetISO8859_1 латиница ISO-8859-1, ECI 3
+	CharsetISO8859_1
+	//CharsetISO8859_5 кириллица ISO-8859-5, ECI 7
+	CharsetISO8859_5
+	//CharsetWindows1251 кириллица Windows-1251, ECI 22
+	CharsetWindows1251
+)
+
+//Режим заголовка ECI
+const modeECI = 0x7
+
+//Назначение ECI и таблица однобайтовой кодировки, nil для UTF-8
+type charset struct {
+	eci   int
+	table *charmap.Charmap
+}
+
+var charsets = map[Charset]*charset{
+	CharsetDefault:     nil,
+	CharsetUTF8:        {26, nil},
+	CharsetISO8859_1:   {3, charmap.ISO8859_1},
+	CharsetISO8859_5:   {7, charmap.ISO8859_5},
+	CharsetWindows1251: {22, charmap.Windows1251},
+}
+
+//Кодировки, перебираемые в CharsetAuto, в порядке предпочтения
+var autoCharsets = []Charset{CharsetISO8859_1, CharsetISO8859_5, CharsetWindows1251, CharsetUTF8}
+
+//Разбиение строки на сегменты в заданной кодировке
+func splitCharset(content string, head symbolHeader, opt Options) (segments []segment, length int) {
+	if cs := opt.Charset; cs == CharsetAuto {
+		ascii := true
+		for i := 0; i < len(content); i++ {
+			if content[i] >= 0x80 {
+				ascii = false
+				break
+			}
+		}
+		if ascii {
+			opt.Charset = CharsetDefault
+			return splitCharset(content, head, opt)
+		}
+		for _, c := range autoCharsets {
+			opt.Charset = c
+			if s, l := splitCharset(content, head, opt); s != nil && (segments == nil || l < length) {
+				segments, length = s, l
+			}
+		}
+		return
+	}
+
+	table := charsets[opt.Charset]
+	segments = splitSegments(content, head, table, opt.FNC1 != FNC1None)
+	if segments == nil {
+		return nil, 0
+	}
+	switch opt.FNC1 {
+	case FNC1First:
+		segments = append([]segment{{mode: modeFNC1First}}, segments...)
+	case FNC1Second:
+		app, _ := appIndicator(opt.AppIndicator)
+		segments = append([]segment{{mode: modeFNC1Second, value: app}}, segments...)
+	}
+	if table != nil {
+		segments = append([]segment{{mode: modeECI, value: table.eci}}, segments...)
+	}
+	for _, seg := range segments {
+		length += lenSegment(seg, head)
+	}
+	return
+}
+
+//Длина символа в байтах в кодировке, 0 если символ не кодируется, size длина символа в строке
+func lenCharset(r rune, size int, table *charset) int {
+	if table == nil || table.table == nil {
+		return size
+	}
+	if _, ok := table.table.EncodeRune(r); ok {
+		return 1
+	}
+	return 0
+}
+
+//Перекодирование строки в однобайтовую кодировку
+func encodeCharset(content string, table *charset) string {
+	if table == nil || table.table == nil {
+		return content
+	}
+	buf := make([]byte, 0, len(content))
+	for _, r := range content {
+		b, _ := table.table.EncodeRune(r)
+		buf = append(buf, b)
+	}
+	return string(buf)
+}
+
+//Длина назначения ECI в битах
+func lenECI(eci int) int {
+	switch {
+	case eci < 1<<7:
+		return 8
+	case eci < 1<<14:
+		return 16
+	}
+	return 24
+}
+
+//Запись назначения ECI в последовательность
+func putECI(buf *bitBuffer, eci int) {
+	switch lenECI(eci) {
+	case 8:
+		buf.put(eci, 8)
+	case 16:
+		buf.put(0x8000|eci, 16)
+	default:
+		buf.put(0xc00000|eci, 24)
+	}
+}
@@ -0,0 +1,70 @@
+package goqr
+
+import "testing"
+
+//Назначение ECI длиной 8, 16 и 24 бита
+func TestPutECI(t *testing.T) {
+	for _, tt := range []struct {
+		eci, value, length int
+	}{
+		{3, 0x03, 8},
+		{127, 0x7f, 8},
+		{1000, 0x8000 | 1000, 16},
+		{16383, 0x8000 | 16383, 16},
+		{100000, 0xc00000 | 100000, 24},
+	} {
+		buf := newBitBuffer(24)
+		putECI(buf, tt.eci)
+		if buf.len() != tt.length || lenECI(tt.eci) != tt.length {
+			t.Errorf("eci %d: %d bits, lenECI %d, want %d", tt.eci, buf.len(), lenECI(tt.eci), tt.length)
+			continue
+		}
+		value := 0
+		for i := 0; i < buf.len(); i++ {
+			value = value<<1 | int(buf.bit(i))
+		}
+		if value != tt.value {
+			t.Errorf("eci %d: value %#x, want %#x", tt.eci, value, tt.value)
+		}
+	}
+}
+
+//CharsetAuto выбирает однобайтовую кодировку, ASCII без ECI и UTF-8 для смешанных алфавитов
+func TestSplitCharsetAuto(t *testing.T) {
+	for _, tt := range []struct {
+		content string
+		//Назначение ECI, 0 без заголовка
+		eci int
+	}{
+		{"hello, world", 0},
+		{"Привет, мир", 7},
+		{"Grüße", 3},
+		{"Привет, Grüße", 26},
+	} {
+		segments, _ := splitCharset(tt.content, qrHeader(0), Options{Charset: CharsetAuto})
+		if segments == nil {
+			t.Fatalf("%q: not encoded", tt.content)
+		}
+		eci := 0
+		if segments[0].mode == modeECI {
+			eci = segments[0].value
+		}
+		if eci != tt.eci {
+			t.Errorf("%q: eci %d, want %d", tt.content, eci, tt.eci)
+		}
+	}
+}
+
+//Заданная кодировка без нужных символов возвращает ошибку
+func TestEncodeCharsetWrong(t *testing.T) {
+	if _, err := Encode("Привет", Options{Charset: CharsetISO8859_1}); err == nil {
+		t.Error("cyrillic in ISO-8859-1: want error")
+	}
+	code, err := Encode("Привет", Options{Charset: CharsetWindows1251})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.Version() != 1 {
+		t.Errorf("version %d, want 1", code.Version())
+	}
+}
@@ -0,0 +1,48 @@
+package goqr
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+)
+
+//FormatEPS encapsulated postscript, модули рисуются путями в DeviceCMYK
+const FormatEPS Format = "eps"
+
+func init() {
+	RegisterRenderer(FormatEPS, RendererFunc(renderEPS), ".eps", ".ps")
+}
+
+//Вывод символа в eps с тихой зоной. Картинка в eps не выводится, модули под ней остаются
+func renderEPS(w io.Writer, code *Code, style Style) error {
+	style.Logo = nil
+	module := style.modulePoints(code)
+	width, height := code.Width()+2*style.QuietZone, code.Height()+2*style.QuietZone
+	boxWidth, boxHeight := module*float64(width), module*float64(height)
+
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "%%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(buf, "%%%%Creator: goqr\n")
+	fmt.Fprintf(buf, "%%%%BoundingBox: 0 0 %d %d\n", int(math.Ceil(boxWidth)), int(math.Ceil(boxHeight)))
+	fmt.Fprintf(buf, "%%%%HiResBoundingBox: 0 0 %s %s\n", pdfNumber(boxWidth), pdfNumber(boxHeight))
+	fmt.Fprintf(buf, "%%%%LanguageLevel: 2\n%%%%Pages: 1\n%%%%EndComments\n")
+	//Прямоугольник x y w h
+	fmt.Fprintf(buf, "%%%%BeginProlog\n/r {4 2 roll moveto 1 index 0 rlineto 0 exch rlineto neg 0 rlineto closepath} bind def\n%%%%EndProlog\n")
+	fmt.Fprintf(buf, "%%%%Page: 1 1\ngsave\n")
+	//Модули с началом в левом верхнем углу
+	fmt.Fprintf(buf, "0 %s translate %s %s scale\n", pdfNumber(boxHeight), pdfNumber(module), pdfNumber(-module))
+	fmt.Fprintf(buf, "%s\n0 0 %d %d rectfill\n", epsFill(style.Light), width, height)
+	fmt.Fprintf(buf, "%s\nnewpath\n", epsFill(style.Dark))
+	for _, r := range darkRects(code, style) {
+		fmt.Fprintf(buf, "%d %d %d %d r\n", r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+	}
+	fmt.Fprintf(buf, "fill\ngrestore\nshowpage\n%%%%EOF\n")
+	return buf.Flush()
+}
+
+//Цвет заливки в DeviceCMYK
+func epsFill(c color.Color) string {
+	return cmykNumbers(c) + " setcmykcolor"
+}
@@ -0,0 +1,57 @@
+package goqr
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+//Рамки eps в пунктах: BoundingBox округляется вверх, HiResBoundingBox точный; заливки setcmykcolor
+func TestRenderEPS(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		opts  []Option
+		lines []string
+	}{
+		{"module size", []Option{WithModuleSize(3)}, []string{"%%BoundingBox: 0 0 87 87\n", "%%HiResBoundingBox: 0 0 87 87\n", "0 0 29 29 rectfill\n"}},
+		{"30 mm", []Option{WithPhysicalSize(30, UnitMM)}, []string{"%%BoundingBox: 0 0 86 86\n", "%%HiResBoundingBox: 0 0 85.0394 85.0394\n", "0 0 29 29 rectfill\n"}},
+		{"1 inch", []Option{WithPhysicalSize(1, UnitInch), WithQuietZone(0)}, []string{"%%BoundingBox: 0 0 72 72\n", "%%HiResBoundingBox: 0 0 72 72\n", "0 0 21 21 rectfill\n"}},
+	} {
+		var buf bytes.Buffer
+		opts := append([]Option{WithFormat(FormatEPS), WithColors(color.RGBA{0, 0, 255, 255}, color.White)}, tt.opts...)
+		if err := Generate(&buf, "HELLO WORLD", opts...); err != nil {
+			t.Fatal(err)
+		}
+		eps := buf.String()
+		if !strings.HasPrefix(eps, "%!PS-Adobe-3.0 EPSF-3.0\n") || !strings.HasSuffix(eps, "showpage\n%%EOF\n") {
+			t.Errorf("%s: eps header or trailer wrong", tt.name)
+		}
+		for _, s := range append(tt.lines, "0 0 0 0 setcmykcolor\n0 0 ", "1 1 0 0 setcmykcolor\nnewpath\n") {
+			if !strings.Contains(eps, s) {
+				t.Errorf("%s: eps without %q", tt.name, s)
+			}
+		}
+	}
+}
+
+//Картинка в eps не выводится, модули под ней рисуются
+func TestRenderEPSLogo(t *testing.T) {
+	code, err := Encode("HELLO WORLD", Options{Level: LevelH})
+	if err != nil {
+		t.Fatal(err)
+	}
+	style := defaultConfig().style
+	var plain, logo bytes.Buffer
+	if err := renderEPS(&plain, code, style); err != nil {
+		t.Fatal(err)
+	}
+	style.Logo = image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	if err := renderEPS(&logo, code, style); err != nil {
+		t.Fatal(err)
+	}
+	if plain.String() != logo.String() {
+		t.Error("eps with logo differs")
+	}
+}
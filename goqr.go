@@ -7,21 +7,28 @@ import (
 	"image/color/palette"
 	"image/draw"
 	"image/gif"
-	"image/jpeg"
-	"image/png"
-	"io/ioutil"
 	"math"
-	"net/http"
-	"os"
-	"strings"
+	"unicode/utf8"
 )
 
+var maxDataL = []int{
+	152, 272, 440, 640, 864, 1088, 1248, 1552, 1856, 2192,
+	2592, 2960, 3424, 3688, 4184, 4712, 5176, 5768, 6360, 6888,
+	7456, 8048, 8752, 9392, 10208, 10960, 11744, 12248, 13048, 13880,
+	14744, 15640, 16568, 17528, 18448, 19472, 20528, 21616, 22496, 23648,
+}
 var maxDataM = []int{
 	128, 224, 352, 512, 688, 864, 992, 1232, 1456, 1728,
 	2032, 2320, 2672, 2920, 3320, 3624, 4056, 4504, 5016, 5352,
 	5712, 6256, 6880, 7312, 8000, 8496, 9024, 9544, 10136, 10984,
 	11640, 12328, 13048, 13800, 14496, 15312, 15936, 16816, 17728, 18672,
 }
+var maxDataQ = []int{
+	104, 176, 272, 384, 496, 608, 704, 880, 1056, 1232,
+	1440, 1648, 1952, 2088, 2360, 2600, 2936, 3176, 3560, 3880,
+	4096, 4544, 4912, 5312, 5744, 6032, 6464, 6968, 7288, 7880,
+	8264, 8920, 9368, 9848, 10288, 10832, 11408, 12016, 12656, 13328,
+}
 var maxDataH = []int{
 	72, 128, 208, 288, 368, 480, 528, 688, 800, 976,
 	1120, 1264, 1440, 1576, 1784, 2024, 2264, 2504, 2728, 3080,
@@ -29,12 +36,24 @@ var maxDataH = []int{
 	6344, 6760, 7208, 7688, 7888, 8432, 8768, 9136, 9776, 10208,
 }
 
+var blocksL = []int{
+	1, 1, 1, 1, 1, 2, 2, 2, 2, 4,
+	4, 4, 4, 4, 6, 6, 6, 6, 7, 8,
+	8, 9, 9, 10, 12, 12, 12, 13, 14, 15,
+	16, 17, 18, 19, 19, 20, 21, 22, 24, 25,
+}
 var blocksM = []int{
 	1, 1, 1, 2, 2, 4, 4, 4, 5, 5,
 	5, 8, 9, 9, 10, 10, 11, 13, 14, 16,
 	17, 17, 18, 20, 21, 23, 25, 26, 28, 29,
 	31, 33, 35, 37, 38, 40, 43, 45, 47, 49,
 }
+var blocksQ = []int{
+	1, 1, 2, 2, 4, 4, 6, 6, 8, 8,
+	8, 10, 12, 16, 12, 17, 16, 18, 21, 20,
+	23, 23, 25, 27, 29, 34, 34, 35, 38, 40,
+	43, 45, 48, 51, 53, 56, 59, 62, 65, 68,
+}
 var blocksH = []int{
 	1, 1, 2, 4, 4, 4, 5, 6, 8, 8,
 	11, 11, 16, 16, 18, 16, 19, 21, 25, 25,
@@ -42,12 +61,24 @@ var blocksH = []int{
 	51, 54, 57, 60, 63, 66, 70, 74, 77, 81,
 }
 
+var byteCorectL = []int{
+	7, 10, 15, 20, 26, 18, 20, 24, 30, 18,
+	20, 24, 26, 30, 22, 24, 28, 30, 28, 28,
+	28, 28, 30, 30, 26, 28, 30, 30, 30, 30,
+	30, 30, 30, 30, 30, 30, 30, 30, 30, 30,
+}
 var byteCorectM = []int{
 	10, 16, 26, 18, 24, 16, 18, 22, 22, 26,
 	30, 22, 22, 24, 24, 28, 28, 26, 26, 26,
 	26, 28, 28, 28, 28, 28, 28, 28, 28, 28,
 	28, 28, 28, 28, 28, 28, 28, 28, 28, 28,
 }
+var byteCorectQ = []int{
+	13, 22, 18, 26, 18, 24, 18, 22, 20, 24,
+	28, 26, 24, 20, 30, 24, 28, 28, 26, 30,
+	28, 30, 30, 30, 30, 28, 30, 30, 30, 30,
+	30, 30, 30, 30, 30, 30, 30, 30, 30, 30,
+}
 var byteCorectH = []int{
 	17, 28, 22, 16, 22, 28, 26, 26, 24, 28,
 	24, 28, 22, 24, 24, 30, 28, 28, 26, 28,
@@ -56,14 +87,96 @@ var byteCorectH = []int{
 }
 
 const (
-	levelCorrectM = 0x5e7c
-	levelCorrectH = 0x1ce7
+	levelBitsL = 1
+	levelBitsM = 0
+	levelBitsQ = 3
+	levelBitsH = 2
 )
 
+//Level уровень коррекции ошибок
+type Level byte
+
+const (
+	//LevelAuto M без картинки и H с картинкой
+	LevelAuto Level = iota
+	//LevelL восстанавливает до 7% данных
+	LevelL
+	//LevelM восстанавливает до 15% данных
+	LevelM
+	//LevelQ восстанавливает до 25% данных
+	LevelQ
+	//LevelH восстанавливает до 30% данных
+	LevelH
+)
+
+//Таблицы емкости и блоков для уровня коррекции
+type levelTable struct {
+	maxData, blocks, byteCorect *[]int
+	levelBits                   int
+}
+
+var levelTables = map[Level]levelTable{
+	LevelL: {&maxDataL, &blocksL, &byteCorectL, levelBitsL},
+	LevelM: {&maxDataM, &blocksM, &byteCorectM, levelBitsM},
+	LevelQ: {&maxDataQ, &blocksQ, &byteCorectQ, levelBitsQ},
+	LevelH: {&maxDataH, &blocksH, &byteCorectH, levelBitsH},
+}
+
+//Options дополнительные настройки генерации
+type Options struct {
+	//Level уровень коррекции ошибок
+	Level Level
+	//Mask шаблон маски, по умолчанию выбирается по наименьшему штрафу
+	Mask Mask
+	//Charset кодировка байтового режима, по умолчанию UTF-8 без ECI
+	Charset Charset
+	//Append вывод структурированного объединения, если данные не помещаются в один qr
+	Append Append
+	//FNC1 режим данных GS1 или отраслевого стандарта
+	FNC1 FNC1
+	//AppIndicator индикатор приложения для FNC1Second: две цифры или одна латинская буква
+	AppIndicator string
+	//MinVersion наименьшая версия 1–40, 0 без ограничения
+	MinVersion int
+	//MaxVersion наибольшая версия 1–40, 0 без ограничения
+	MaxVersion int
+}
+
+//Настройки Options в виде Option, проверка значений выполняется только в функциях With*
+func (o Options) options() []Option {
+	opts := []Option{WithLevel(o.Level), WithMask(o.Mask), WithCharset(o.Charset), WithAppend(o.Append), WithFNC1(o.FNC1, o.AppIndicator)}
+	if o.MinVersion != 0 {
+		opts = append(opts, WithMinVersion(o.MinVersion))
+	}
+	if o.MaxVersion != 0 {
+		opts = append(opts, WithMaxVersion(o.MaxVersion))
+	}
+	return opts
+}
+
+//Диапазон номеров версий от 0 до 39 с учетом ограничений
+func (o Options) versions() (min, max int) {
+	min, max = 0, 39
+	if o.MinVersion > 0 {
+		min = o.MinVersion - 1
+	}
+	if o.MaxVersion > 0 {
+		max = o.MaxVersion - 1
+	}
+	return
+}
+
 var polinom = map[int][]int{
+	2:  {25, 1},
+	5:  {113, 164, 166, 119, 10},
+	6:  {166, 0, 134, 5, 176, 15},
 	7:  {87, 229, 146, 149, 238, 102, 21},
+	9:  {95, 246, 137, 231, 235, 149, 11, 123, 36},
 	10: {251, 67, 46, 61, 118, 70, 64, 94, 32, 45},
+	8:  {175, 238, 208, 249, 215, 252, 196, 28},
+	12: {102, 43, 98, 121, 187, 113, 198, 143, 131, 87, 157, 66},
 	13: {74, 152, 176, 100, 86, 100, 106, 104, 130, 218, 206, 140, 78},
+	14: {199, 249, 155, 48, 190, 124, 218, 137, 216, 87, 207, 59, 22, 91},
 	15: {8, 183, 61, 91, 202, 37, 51, 58, 58, 237, 140, 124, 5, 99, 105},
 	16: {120, 104, 107, 109, 102, 161, 76, 3, 91, 191, 147, 169, 182, 194, 225, 120},
 	17: {43, 139, 206, 78, 43, 239, 123, 206, 214, 147, 24, 99, 150, 39, 243, 163, 136},
@@ -122,59 +235,51 @@ var qrBlocks = []int{
 	149, 153, 157, 161, 165, 169, 173, 177,
 }
 
-var codeVersion = [][]int{
-	{}, {}, {}, {}, {}, {},
-	{0x2, 0x1e, 0x26},
-	{0x11, 0x1c, 0x38},
-	{0x37, 0x18, 0x4},
-	{0x29, 0x3e, 0x0},
-	{0xf, 0x3a, 0x3c},
-	{0xd, 0x24, 0x1a},
-	{0x2b, 0x20, 0x26},
-	{0x35, 0x6, 0x22},
-	{0x13, 0x2, 0x1e},
-	// {0x,0x,0x},	011100 010001 011100
-	// {0x,0x,0x},	111010 010101 100000
-	// {0x,0x,0x},	100100 110011 100100
-	// {0x,0x,0x},	000010 110111 011000
-	// {0x,0x,0x},	000000 101001 111110
-	// {0x,0x,0x},	100110 101101 000010
-	// {0x,0x,0x},	111000 001011 000110
-	// {0x,0x,0x},	011110 001111 111010
-	// {0x,0x,0x},	001101 001101 100100
-	// {0x,0x,0x},	101011 001001 011000
-	// {0x,0x,0x},	110101 101111 011100
-	// {0x,0x,0x},	010011 101011 100000
-	// {0x,0x,0x},	010001 110101 000110
-	// {0x,0x,0x},	110111 110001 111010
-	// {0x,0x,0x},	101001 010111 111110
-	// {0x,0x,0x},	001111 010011 000010
-	// {0x,0x,0x},	101000 011000 101101
-	// {0x,0x,0x},	001110 011100 010001
-	// {0x,0x,0x},	010000 111010 010101
-	// {0x,0x,0x},	110110 111110 101001
-	// {0x,0x,0x},	110100 100000 001111
-	// {0x,0x,0x},	010010 100100 110011
-	// {0x,0x,0x},	001100 000010 110111
-	// {0x,0x,0x},	101010 000110 001011
-	// {0x,0x,0x},	111001 000100 010101
-}
-
-var coordAnchor = [][][]int{
+//Полином BCH(18,6) для кода версии
+const polinomVersion = 0x1f25
+
+//Координаты центров якорей по версиям
+var posAnchor = [][]int{
 	{},
-	{{18, 18}},
-	{{22, 22}},
-	{{26, 26}},
-	{{30, 30}},
-	{{34, 34}},
-	{{6, 22}, {22, 6}, {22, 22}, {22, 38}, {38, 22}, {38, 38}},
-	{{6, 24}, {24, 6}, {24, 24}, {24, 42}, {42, 24}, {42, 42}},
-	{{6, 26}, {26, 6}, {26, 26}, {26, 46}, {46, 26}, {46, 46}},
-	{{6, 28}, {28, 6}, {28, 28}, {28, 50}, {50, 28}, {50, 50}},
-	{{6, 30}, {30, 6}, {30, 30}, {30, 54}, {54, 30}, {54, 54}},
-	{{6, 32}, {32, 6}, {32, 32}, {32, 58}, {58, 32}, {58, 58}},
-	{{6, 34}, {34, 6}, {34, 34}, {34, 62}, {62, 34}, {62, 62}},
-	{{6, 26}, {6, 46}, {26, 6}, {26, 26}, {26, 46}, {26, 66}, {46, 6}, {46, 26}, {46, 46}, {46, 66}, {66, 26}, {66, 46}, {66, 66}},
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+	{6, 28, 50},
+	{6, 30, 54},
+	{6, 32, 58},
+	{6, 34, 62},
+	{6, 26, 46, 66},
+	{6, 26, 48, 70},
+	{6, 26, 50, 74},
+	{6, 30, 54, 78},
+	{6, 30, 56, 82},
+	{6, 30, 58, 86},
+	{6, 34, 62, 90},
+	{6, 28, 50, 72, 94},
+	{6, 26, 50, 74, 98},
+	{6, 30, 54, 78, 102},
+	{6, 28, 54, 80, 106},
+	{6, 32, 58, 84, 110},
+	{6, 30, 58, 86, 114},
+	{6, 34, 62, 90, 118},
+	{6, 26, 50, 74, 98, 122},
+	{6, 30, 54, 78, 102, 126},
+	{6, 26, 52, 78, 104, 130},
+	{6, 30, 56, 82, 108, 134},
+	{6, 34, 60, 86, 112, 138},
+	{6, 30, 58, 86, 114, 142},
+	{6, 34, 62, 90, 118, 146},
+	{6, 30, 54, 78, 102, 126, 150},
+	{6, 24, 50, 76, 102, 128, 154},
+	{6, 28, 54, 80, 106, 132, 158},
+	{6, 32, 58, 84, 110, 136, 162},
+	{6, 26, 54, 82, 110, 138, 166},
+	{6, 30, 58, 86, 114, 142, 170},
 }
 
 const (
@@ -191,84 +296,50 @@ const (
 )
 
 //QRGenerate генерирует qr
+//
+//Deprecated: используйте GenerateFile с WithLogoFile и WithLogoRatio
 func QRGenerate(content, imagePath, qrPath string, sizeImg float64) error {
-	if qrPath == "" {
-		return errors.New("qrPath is nil")
-	}
-
-	maxData := &maxDataM
-	blocks := &blocksM
-	byteCorect := &byteCorectM
-	levelCorrect := levelCorrectM
-	var gachi interface{}
-	var maxSizeGachi int
-
+	var opts []Option
 	if imagePath != "" {
-		file, err := os.OpenFile(imagePath, os.O_RDONLY, 0777)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
-		buf, _ := ioutil.ReadFile(imagePath)
-		switch http.DetectContentType(buf) {
-		case "image/jpeg":
-			gachi, err = jpeg.Decode(file)
-			if err != nil {
-				return err
-			}
-			if !strings.HasSuffix(qrPath, ".jpg") {
-				return errors.New("QR not jpg")
-			}
-		case "image/png":
-			gachi, err = png.Decode(file)
-			if err != nil {
-				return err
-			}
-			if !strings.HasSuffix(qrPath, ".png") {
-				return errors.New("QR not png")
-			}
-		case "image/gif":
-			gachi, err = gif.DecodeAll(file)
-			if err != nil {
-				return err
-			}
-			if !strings.HasSuffix(qrPath, ".gif") {
-				return errors.New("QR not gif")
-			}
-		default:
-			return errors.New("image wrong type")
-		}
+		opts = append(opts, WithLogoFile(imagePath), WithLogoRatio(sizeImg))
+	}
+	return GenerateFile(content, qrPath, opts...)
+}
 
-		maxData = &maxDataH
-		blocks = &blocksH
-		byteCorect = &byteCorectH
-		levelCorrect = levelCorrectH
-	} else {
-		sizeImg = 0
+//Encode кодирует строку в матрицу qr без вывода изображения, LevelAuto означает LevelM.
+//Структурированное объединение недоступно, используйте EncodeAppend
+func Encode(content string, opt Options) (*Code, error) {
+	table, err := checkOptions(&opt)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Append != AppendNone {
+		return nil, errors.New("append not supported in encode, use EncodeAppend")
 	}
 
-	//Перевод строки в двоичную последовательность
-	length, data := utfToBit(content)
-	//Выбор версии QR кода и длины системных данных
-	version, lenSystemData, err := howToVersion(length, maxData, byteCorect, blocks)
+	//Выбор версии QR кода и разбиение строки на сегменты
+	version, segments, _, err := howToVersion(content, opt, table.maxData)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	dataImg, mask := buildQR(segments, version, table, opt.Mask)
+	return &Code{modules: dataImg, version: version, level: opt.Level, mask: Mask0 + Mask(mask), qr: true}, nil
+}
 
-	maxSizeGachi = int(math.Sqrt(float64((qrBlocks[version]*qrBlocks[version])-240-(len(coordAnchor[version])*25)-(qrBlocks[version]*2)) * sizeImg))
-	if maxSizeGachi%2 == 0 {
-		maxSizeGachi--
+//Проверка настроек qr, LevelAuto заменяется на LevelM
+func checkOptions(opt *Options) (levelTable, error) {
+	if _, err := newConfig(opt.options()); err != nil {
+		return levelTable{}, err
 	}
-	//Запись системных данных в начало массива
-	data = addServicesData(content, version, lenSystemData, maxData, data)
-	//Дозаполнение пустышками до необходимой длины
-	addVoidData(lenSystemData, length, version, maxData, &data)
-	//Пстроение блоков
-	block, byteBlock, sizeBlock := buildBlock(version, maxData, blocks, &data)
-	//Создание байт коррекции
-	countByteCorect, corectBlock, sizeCorrBlock := buildCorectBlock(version, block, byteCorect, &byteBlock)
-	//Групирование блоков данных
-	data = groupData(sizeBlock, sizeCorrBlock, countByteCorect, &byteBlock, &corectBlock)
+	if opt.Level == LevelAuto {
+		opt.Level = LevelM
+	}
+	return levelTables[opt.Level], nil
+}
+
+//Построение модулей qr по сегментам, возвращает модули и выбранную маску
+func buildQR(segments []segment, version int, table levelTable, mask Mask) ([][]byte, int) {
+	data := buildCodewords(segments, qrHeader(groupVersion(version)), version, table)
 
 	//Рисование
 	size := qrBlocks[version]
@@ -278,180 +349,166 @@ func QRGenerate(content, imagePath, qrPath string, sizeImg float64) error {
 	}
 	searchPoint(&dataImg)
 	syncLine(&dataImg)
-	maskInfo(&dataImg, levelCorrect)
+	maskInfo(&dataImg, 0)
 	codeVer(&dataImg, version)
 	anchor(&dataImg, version)
-	write(&dataImg, &data)
+	writeBits(&dataImg, data, 6)
+	return dataImg, chooseMask(&dataImg, table.levelBits, mask)
+}
 
-	//Вывод изображения
-	file1, err := os.OpenFile(qrPath, os.O_CREATE|os.O_RDWR, 0777)
-	if err != nil {
-		return err
+//Последовательность данных и коррекции с чередованием блоков для версии qr или rmqr
+func buildCodewords(segments []segment, head symbolHeader, version int, table levelTable) *bitBuffer {
+	maxData, blocks, byteCorect := table.maxData, table.blocks, table.byteCorect
+	//Запись сегментов в начало последовательности
+	data := addServicesData(segments, head, (*maxData)[version])
+	//Дозаполнение терминатором и пустышками до необходимой длины
+	addVoidTerminator((*maxData)[version], head.lenTerminator, data)
+	//Пстроение блоков
+	block, byteBlock, sizeBlock := buildBlock(version, maxData, blocks, data)
+	//Создание байт коррекции
+	countByteCorect, corectBlock, sizeCorrBlock := buildCorectBlock(version, block, byteCorect, &byteBlock)
+	//Групирование блоков данных
+	return groupData(sizeBlock, sizeCorrBlock, countByteCorect, &byteBlock, &corectBlock)
+}
+
+//Сторона области картинки в модулях, нечетная для симметрии в символе нечетного размера
+func logoModules(version int, ratio float64) int {
+	size := qrBlocks[version]
+	maxSizeGachi := int(math.Sqrt(float64((size*size)-240-(len(coordAnchor(version))*25)-(size*2)) * ratio))
+	if maxSizeGachi%2 == 0 {
+		maxSizeGachi--
 	}
+	return maxSizeGachi
+}
 
-	if img2, ok := gachi.(image.Image); ok {
-		if err := png.Encode(file1, paintImage(size, maxSizeGachi, &dataImg, img2)); err != nil {
-			return err
-		}
-	} else if img2, ok := gachi.(*gif.GIF); ok {
-		if err := gif.EncodeAll(file1, paintGIF(size, maxSizeGachi, &dataImg, img2)); err != nil {
-			return err
-		}
-	} else {
-		if err := png.Encode(file1, paintImage(size, maxSizeGachi, &dataImg, nil)); err != nil {
-			return err
-		}
+//Рисование qr с картинкой или гифкой
+func paintQR(version int, dataImg *[][]byte, s Style) interface{} {
+	size := qrBlocks[version]
+	maxSizeGachi := logoModules(version, s.LogoRatio)
+	if maxSizeGachi < 1 {
+		return paintImage(size, maxSizeGachi, dataImg, nil, s)
+	}
+
+	if img2, ok := s.Logo.(image.Image); ok {
+		return paintImage(size, maxSizeGachi, dataImg, img2, s)
+	} else if img2, ok := s.Logo.(*gif.GIF); ok {
+		return paintGIF(size, maxSizeGachi, dataImg, img2, s)
 	}
-	return nil
+	return paintImage(size, maxSizeGachi, dataImg, nil, s)
 }
 
-//Перевод строки в двоичную последовательность
-func utfToBit(content string) (length int, dataBit []int) {
-	count := 0
-	length = len(content) * 8
-	dataBit = make([]int, length)
-	for l, i := len(content), 0; i < l; i++ {
-		mask := 0x80
-		a := int(content[i])
-		for mask > 0 {
-			if mask&a != 0 {
-				dataBit[count] = 1
-			}
-			mask >>= 1
-			count++
+//Запись строки в двоичную последовательность в режиме кодирования
+func utfToBit(buf *bitBuffer, content string, mode int) {
+	switch mode {
+	case modeNumeric:
+		numericToBit(buf, content)
+	case modeAlphanum:
+		alphanumToBit(buf, content)
+	case modeKanji:
+		kanjiToBit(buf, content)
+	default:
+		for i := 0; i < len(content); i++ {
+			buf.put(int(content[i]), 8)
 		}
 	}
-	return
 }
 
-//Выбор версии QR кода и длины системных данных
-func howToVersion(length int, maxData, byteCorect, blocks *[]int) (version int, lenSystemData int, err error) {
-	for i := 0; i < 40; i++ {
-		max := (*maxData)[i]
-		size := length + 20
-		if size > max {
-			continue
-		}
-		switch {
-		case i < 9:
-			if size >= max {
-				i++
-				if i == 9 {
-					version = i
-					lenSystemData = 20
-					break
-				}
-			}
-			version = i
-			lenSystemData = 12
-		case i >= 9 && i < 26:
-			if size >= max {
-				i++
-				if i == 26 {
-					version = i
-					lenSystemData = 20
-					break
-				}
-			}
-			version = i
-			lenSystemData = 20
-		case i >= 26 && i < 40:
-			if size >= max {
-				i++
-				if i == 40 {
-					err = errors.New("data's oversize")
-					break
-				}
-			}
-			version = i
-			lenSystemData = 20
-		}
-		break
+//Длина строки в битах в режиме кодирования
+func lenData(content string, mode int) int {
+	switch mode {
+	case modeNumeric:
+		return len(content)/3*10 + []int{0, 4, 7}[len(content)%3]
+	case modeAlphanum:
+		return len(content)/2*11 + len(content)%2*6
+	case modeKanji:
+		return utf8.RuneCountInString(content) * 13
 	}
-	return
+	return len(content) * 8
 }
 
-//Запись системных данных в начало массива
-func addServicesData(content string, version int, lenSystemData int, maxData *[]int, dataBit []int) []int {
-	newData := make([]int, (*maxData)[version])
-	newData[1] = 1
-	mask := 1 << (lenSystemData - 4 - 1)
-	countSymbol := len(content)
-	for i := 4; i < lenSystemData; i++ {
-		if countSymbol&mask != 0 {
-			newData[i] = 1
+var errOversize = errors.New("data's oversize")
+
+//Выбор версии QR кода и разбиения на сегменты
+func howToVersion(content string, opt Options, maxData *[]int) (version int, segments []segment, length int, err error) {
+	group := -1
+	min, max := opt.versions()
+	for i := min; i <= max; i++ {
+		if g := groupVersion(i); g != group {
+			group = g
+			segments, length = splitCharset(content, qrHeader(group), opt)
+			if segments == nil {
+				return 0, nil, 0, errors.New("content not in charset")
+			}
+		}
+		if length <= (*maxData)[i] {
+			return i, segments, length, nil
 		}
-		mask >>= 1
 	}
-	copy(newData[lenSystemData:], dataBit)
-	return newData
+	return 0, nil, 0, errOversize
 }
 
-//Дозаполнение пустышками до необходимой длины
-func addVoidData(lenSystemData int, length int, version int, maxData, newData *[]int) {
-	minMultiplyLenData := lenSystemData + length
-	for minMultiplyLenData%8 != 0 {
-		minMultiplyLenData++
-	}
-	f := true
-	for i := minMultiplyLenData; i < (*maxData)[version]; {
-		switch f {
-		case true:
-			(*newData)[i] = 1
-			(*newData)[i+1] = 1
-			(*newData)[i+2] = 1
-			(*newData)[i+4] = 1
-			(*newData)[i+5] = 1
-			i += 8
-			f = false
-		case false:
-			(*newData)[i+3] = 1
-			(*newData)[i+7] = 1
-			i += 8
-			f = true
+//Запись сегментов с режимом и счетчиком символов в последовательность длиной до maxData бит
+func addServicesData(segments []segment, head symbolHeader, maxData int) *bitBuffer {
+	data := newBitBuffer(maxData)
+	for _, seg := range segments {
+		switch seg.mode {
+		case modeECI:
+			data.put(seg.mode, 4)
+			putECI(data, seg.value)
+			continue
+		case modeAppend:
+			data.put(seg.mode, 4)
+			data.put(seg.value, lenAppend-4)
+			continue
+		case modeFNC1First:
+			data.put(seg.mode, 4)
+			continue
+		case modeFNC1Second:
+			data.put(seg.mode, 4)
+			data.put(seg.value, 8)
+			continue
 		}
+		data.put(head.code(seg.mode), head.lenMode)
+		data.put(countSymbol(seg), head.lenCount[seg.mode])
+		utfToBit(data, seg.content, seg.mode)
 	}
+	return data
 }
 
-//Пстроение блоков
-func buildBlock(version int, maxData, blocks, newData *[]int) (block int, byteBlock [][]int, length int) {
-	count := 0
+//Пстроение блоков из байт последовательности без копирования
+func buildBlock(version int, maxData, blocks *[]int, data *bitBuffer) (block int, byteBlock [][]byte, length int) {
+	codewords := data.bytes()
 	block = (*blocks)[version]
-	byteBlock = make([][]int, block)
+	byteBlock = make([][]byte, block)
 	maxByte := (*maxData)[version] / 8
 	size, resid := maxByte/block, maxByte%block
 	for i := 0; i < block; i++ {
+		n := size
 		if resid >= block-i {
-			byteBlock[i] = make([]int, size+1)
-			length += size + 1
-		} else {
-			byteBlock[i] = make([]int, size)
-			length += size
-		}
-		for j := 0; j < len(byteBlock[i]); j++ {
-			byteBlock[i][j] = (*newData)[count]*(2<<6) + (*newData)[count+1]*(2<<5) + (*newData)[count+2]*(2<<4) + (*newData)[count+3]*(2<<3) + (*newData)[count+4]*(2<<2) + (*newData)[count+5]*(2<<1) + (*newData)[count+6]*(2<<0) + (*newData)[count+7]
-			count += 8
+			n++
 		}
+		byteBlock[i] = codewords[length : length+n]
+		length += n
 	}
 	return
 }
 
 //Создание байт коррекции
-func buildCorectBlock(version int, block int, byteCorect *[]int, byteBlock *[][]int) (countByteCorect int, corectBlock [][]int, length int) {
+func buildCorectBlock(version int, block int, byteCorect *[]int, byteBlock *[][]byte) (countByteCorect int, corectBlock [][]byte, length int) {
 	countByteCorect = (*byteCorect)[version]
 	polinomCorect := polinom[countByteCorect]
-	corectBlock = make([][]int, block)
+	corectBlock = make([][]byte, block)
 	for i := range corectBlock {
 		if len((*byteBlock)[i]) > countByteCorect {
-			corectBlock[i] = make([]int, len((*byteBlock)[i]))
+			corectBlock[i] = make([]byte, len((*byteBlock)[i]))
 			length += len((*byteBlock)[i])
 		} else {
-			corectBlock[i] = make([]int, countByteCorect)
+			corectBlock[i] = make([]byte, countByteCorect)
 			length += countByteCorect
 		}
 		copy(corectBlock[i], (*byteBlock)[i])
 		for range (*byteBlock)[i] {
-			x := corectBlock[i][0]
+			x := int(corectBlock[i][0])
 			copy(corectBlock[i], corectBlock[i][1:])
 			corectBlock[i][len(corectBlock[i])-1] = 0
 			if x == 0 {
@@ -463,7 +520,7 @@ func buildCorectBlock(version int, block int, byteCorect *[]int, byteBlock *[][]
 				if y > 254 {
 					y %= 255
 				}
-				corectBlock[i][j] ^= fieldGalua[y]
+				corectBlock[i][j] ^= byte(fieldGalua[y])
 			}
 		}
 	}
@@ -471,10 +528,10 @@ func buildCorectBlock(version int, block int, byteCorect *[]int, byteBlock *[][]
 }
 
 //Групирование блоков данных
-func groupData(sizBlock, sizeCorrBlock, countByteCorect int, byteBlock, corectBlock *[][]int) (data []int) {
+func groupData(sizBlock, sizeCorrBlock, countByteCorect int, byteBlock, corectBlock *[][]byte) *bitBuffer {
 	count := 0
 	length := sizBlock + sizeCorrBlock
-	data = make([]int, length)
+	data := make([]byte, length)
 	for j := 0; j < length; j++ {
 		for _, v := range *byteBlock {
 			if len(v) > j {
@@ -491,7 +548,7 @@ func groupData(sizBlock, sizeCorrBlock, countByteCorect int, byteBlock, corectBl
 			}
 		}
 	}
-	return
+	return bytesToBits(data)
 }
 
 //Рисование поисковых мояков
@@ -499,36 +556,42 @@ func searchPoint(img *[][]byte) {
 	posx := []int{0, 0, len(*img) - 7}
 	posy := []int{0, len(*img) - 7, 0}
 	for k := 0; k < 3; k++ {
-		x := search1
-		for i := 0; i < 3; i++ {
-			for j := i; j < 7-i; j++ {
-				(*img)[i+posy[k]][j+posx[k]] = x
-				(*img)[j+posy[k]][i+posx[k]] = x
-				(*img)[6-i+posy[k]][j+posx[k]] = x
-				(*img)[j+posy[k]][6-i+posx[k]] = x
-			}
-			(*img)[6-i+posy[k]][6-i+posx[k]] = x
-			if x == search0 {
-				x = search1
-			} else {
-				x = search0
-			}
+		finder(img, posx[k], posy[k])
+	}
+}
+
+//Рисование поискового мояка с разделителем
+func finder(img *[][]byte, posx, posy int) {
+	width := len((*img)[0])
+	x := search1
+	for i := 0; i < 3; i++ {
+		for j := i; j < 7-i; j++ {
+			(*img)[i+posy][j+posx] = x
+			(*img)[j+posy][i+posx] = x
+			(*img)[6-i+posy][j+posx] = x
+			(*img)[j+posy][6-i+posx] = x
 		}
-		(*img)[posy[k]+3][posx[k]+3] = search1
-		for i := -1; i < 8; i++ {
-			if posx[k]+i > -1 && posx[k]+i < len(*img) {
-				if posy[k]+7 < len(*img) {
-					(*img)[posy[k]+7][posx[k]+i] = x
-				} else if posy[k]-1 > -1 {
-					(*img)[posy[k]-1][posx[k]+i] = x
-				}
+		(*img)[6-i+posy][6-i+posx] = x
+		if x == search0 {
+			x = search1
+		} else {
+			x = search0
+		}
+	}
+	(*img)[posy+3][posx+3] = search1
+	for i := -1; i < 8; i++ {
+		if posx+i > -1 && posx+i < width {
+			if posy+7 < len(*img) {
+				(*img)[posy+7][posx+i] = x
+			} else if posy-1 > -1 {
+				(*img)[posy-1][posx+i] = x
 			}
-			if posy[k]+i > -1 && posy[k]+i < len(*img) {
-				if posx[k]+7 < len(*img) {
-					(*img)[posy[k]+i][posx[k]+7] = x
-				} else if posx[k]-1 > -1 {
-					(*img)[posy[k]+i][posx[k]-1] = x
-				}
+		}
+		if posy+i > -1 && posy+i < len(*img) {
+			if posx+7 < width {
+				(*img)[posy+i][posx+7] = x
+			} else if posx-1 > -1 {
+				(*img)[posy+i][posx-1] = x
 			}
 		}
 	}
@@ -596,84 +659,93 @@ func maskInfo(img *[][]byte, code int) {
 
 //Рисование кода версии
 func codeVer(img *[][]byte, version int) {
+	if version < 6 {
+		return
+	}
 	size := len(*img) - 1
-	vers := codeVersion[version]
-	for i := range vers {
-		mask := 0x20
-		for j := 0; mask > 0; j++ {
-			if mask&vers[i] == 0 {
-				(*img)[size-10+i][j] = version0
-				(*img)[j][size-10+i] = version0
-			} else {
-				(*img)[size-10+i][j] = version1
-				(*img)[j][size-10+i] = version1
+	code := versionCode(version + 1)
+	for i := 0; i < 18; i++ {
+		a, b := size-10+i%3, i/3
+		if code&(1<<i) == 0 {
+			(*img)[a][b] = version0
+			(*img)[b][a] = version0
+		} else {
+			(*img)[a][b] = version1
+			(*img)[b][a] = version1
+		}
+	}
+}
+
+//Вычисление кода версии BCH(18,6)
+func versionCode(number int) int {
+	code := number << 12
+	for i := 17; i > 11; i-- {
+		if code&(1<<i) != 0 {
+			code ^= polinomVersion << (i - 12)
+		}
+	}
+	return number<<12 | code
+}
+
+//Координаты центров якорей без пересечения с поисковыми маяками
+func coordAnchor(version int) (coord [][]int) {
+	pos := posAnchor[version]
+	last := len(pos) - 1
+	for i := range pos {
+		for j := range pos {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
 			}
-			mask >>= 1
+			coord = append(coord, []int{pos[i], pos[j]})
 		}
 	}
+	return
 }
 
 //Рисование якорей
 func anchor(img *[][]byte, version int) {
-	coordLisn := coordAnchor[version]
+	coordLisn := coordAnchor(version)
 	for i := range coordLisn {
-		y, x := coordLisn[i][0]-2, coordLisn[i][1]-2
-		k := anchor1
-		for d := 0; d < 2; d++ {
-			for j := d; j < 5-d; j++ {
-				(*img)[d+y][j+x] = k
-				(*img)[j+y][d+x] = k
-				(*img)[4-d+y][j+x] = k
-				(*img)[j+y][4-d+x] = k
-			}
-			if k == anchor0 {
-				k = anchor1
-			} else {
-				k = anchor0
-			}
-		}
-		(*img)[y+2][x+2] = anchor1
+		anchorPoint(img, coordLisn[i][0], coordLisn[i][1], anchor0, anchor1)
 	}
 }
 
-//Рисование данных
-func write(img *[][]byte, data *[]int) {
-	var i int
-	var direct bool
-	bufBit := make([]byte, len(*data)*8)
-	for _, v := range *data {
-		mask := 0x80
-		for mask > 0 {
-			if mask&v != 0 {
-				bufBit[i] = 1
-			}
-			i++
-			mask >>= 1
+//Рисование выравнивающего узора 5x5 с центром в y, x
+func anchorPoint(img *[][]byte, y, x int, light, dark byte) {
+	y, x = y-2, x-2
+	k := dark
+	for d := 0; d < 2; d++ {
+		for j := d; j < 5-d; j++ {
+			(*img)[d+y][j+x] = k
+			(*img)[j+y][d+x] = k
+			(*img)[4-d+y][j+x] = k
+			(*img)[j+y][4-d+x] = k
+		}
+		if k == light {
+			k = dark
+		} else {
+			k = light
 		}
 	}
-	i = 0
+	(*img)[y+2][x+2] = dark
+}
 
-	for x := len(*img) - 1; x > -1; {
-		if x != 6 {
+//Рисование последовательности бит змейкой снизу вверх, пропуская столбец синхронизации
+func writeBits(img *[][]byte, data *bitBuffer, timing int) {
+	var i int
+	var direct bool
+	for x := len((*img)[0]) - 1; x > -1; {
+		if x != timing {
 			if direct {
 				for y := 0; y < len(*img); y++ {
 					for k := 0; k < 2; k++ {
 						if (*img)[y][x-k] == 0 {
 							var a byte
-							if len(bufBit) > i {
-								a = bufBit[i]
+							if data.len() > i {
+								a = data.bit(i)
 								i++
 							}
-							if (x-k)%3 == 0 {
-								if a == 0 {
-									(*img)[y][x-k] = 1
-								} else {
-									(*img)[y][x-k] = 0
-								}
-							} else {
-								(*img)[y][x-k] = a
-							}
-
+							(*img)[y][x-k] = a
 						}
 					}
 				}
@@ -683,19 +755,11 @@ func write(img *[][]byte, data *[]int) {
 					for k := 0; k < 2; k++ {
 						if (*img)[y][x-k] == 0 {
 							var a byte
-							if len(bufBit) > i {
-								a = bufBit[i]
+							if data.len() > i {
+								a = data.bit(i)
 								i++
 							}
-							if (x-k)%3 == 0 {
-								if a == 0 {
-									(*img)[y][x-k] = 1
-								} else {
-									(*img)[y][x-k] = 0
-								}
-							} else {
-								(*img)[y][x-k] = a
-							}
+							(*img)[y][x-k] = a
 						}
 					}
 				}
@@ -709,58 +773,157 @@ func write(img *[][]byte, data *[]int) {
 }
 
 //Вывод модели изображения
-func paintImage(size, maxSizeImg int, dataImg *[][]byte, image2 image.Image) *image.CMYK {
+func paintImage(size, maxSizeImg int, dataImg *[][]byte, image2 image.Image, p Style) draw.Image {
 	var sizeImg, shift int
-	coeff := 1
+	coeff := p.ModuleSize
 	if image2 != nil {
-		coeff = (image2.Bounds().Dx() / maxSizeImg) + 1
-		size = coeff * (size + 8)
+		if c := (image2.Bounds().Dx() / maxSizeImg) + 1; c > coeff {
+			coeff = c
+		}
 		sizeImg = image2.Bounds().Dx() - 1
 		maxSizeImg = maxSizeImg * coeff
 		shift = ((maxSizeImg - sizeImg) / 2)
+	}
+	quiet := coeff * p.QuietZone
+	size = coeff*size + 2*quiet
+	//Прямоугольный символ шире на разницу сторон
+	width := size + coeff*(len((*dataImg)[0])-len(*dataImg))
+
+	rect := image.Rect(0, 0, width, size)
+	var image1 draw.Image
+	//Пиксель светлого и темного модуля в Pix
+	var pix, light, dark []uint8
+	//Индексы пикселей картинки в палитре, nil если картинка рисуется в NRGBA
+	var logoPix []uint8
+	var pal color.Palette
+	if image2 != nil {
+		pal, logoPix = logoPalette(image2, p)
+	}
+	if image2 == nil {
+		//Без картинки хватает двух цветов, png пишется с глубиной 1 бит
+		img := image.NewPaletted(rect, color.Palette{p.Light, p.Dark})
+		image1, pix, light, dark = img, img.Pix, []uint8{0}, []uint8{1}
+	} else if pal != nil {
+		//Цветов картинки вместе со светлым и темным не больше 256
+		img := image.NewPaletted(rect, pal)
+		image1, pix, light, dark = img, img.Pix, []uint8{0}, []uint8{1}
 	} else {
-		size = size + 8
+		img := image.NewNRGBA(rect)
+		l := color.NRGBAModel.Convert(p.Light).(color.NRGBA)
+		d := color.NRGBAModel.Convert(p.Dark).(color.NRGBA)
+		image1, pix, light, dark = img, img.Pix, []uint8{l.R, l.G, l.B, l.A}, []uint8{d.R, d.G, d.B, d.A}
 	}
+	stride := width * len(light)
 
-	rect := image.Rect(0, 0, size, size)
-	image1 := image.NewCMYK(rect)
-
-	x0 := (size / 2) - (maxSizeImg / 2)
-	y0 := (size / 2) - (maxSizeImg / 2)
-
-	for y := 0; y < size; y++ {
-		for x := 0; x < size; x++ {
-			if y > coeff*4-1 && y < size-(coeff*4) && x > coeff*4-1 && x < size-(coeff*4) {
-				if (*dataImg)[(y-(coeff*4))/coeff][(x-(coeff*4))/coeff]%2 == 1 {
-					image1.Set(x, y, color.Black)
-				}
+	//Строка тихой зоны, затем каждая строка модулей заполняется один раз и копируется на высоту модуля
+	fillPix(pix[:stride], light)
+	for y := 1; y < quiet; y++ {
+		copy(pix[y*stride:], pix[:stride])
+	}
+	for i, line := range *dataImg {
+		y := quiet + i*coeff
+		row := pix[y*stride : (y+1)*stride]
+		fillPix(row[:quiet*len(light)], light)
+		for j, module := range line {
+			c := light
+			if module%2 == 1 {
+				c = dark
 			}
+			x := (quiet + j*coeff) * len(light)
+			fillPix(row[x:x+coeff*len(light)], c)
 		}
+		fillPix(row[(width-quiet)*len(light):], light)
+		for k := 1; k < coeff; k++ {
+			copy(pix[(y+k)*stride:], row)
+		}
+	}
+	for y := size - quiet; y < size; y++ {
+		copy(pix[y*stride:], pix[:stride])
 	}
+
 	if image2 != nil {
-		for y := 0; y < maxSizeImg; y++ {
-			for x := 0; x < maxSizeImg; x++ {
-				if x > maxSizeImg-shift-1 || x < shift || y > maxSizeImg-shift-1 || y < shift {
-					image1.Set(x0+x, y0+y, color.White)
-				} else {
-					image1.Set(x0+x, y0+y, image2.At(x-shift, y-shift))
+		x0 := (size / 2) - (maxSizeImg / 2)
+		y0 := (size / 2) - (maxSizeImg / 2)
+		frame := image.Rect(x0, y0, x0+maxSizeImg, y0+maxSizeImg)
+		logo := image.Rect(x0+shift, y0+shift, x0+maxSizeImg-shift, y0+maxSizeImg-shift)
+		if logoPix == nil {
+			//Светлая рамка и картинка без смешивания
+			draw.Draw(image1, frame, image.NewUniform(p.Light), image.Point{}, draw.Src)
+			draw.Draw(image1, logo, image2, image2.Bounds().Min, draw.Src)
+			return image1
+		}
+		//Светлая рамка и индексы картинки, обрезанные по ее размеру, как в draw.Draw
+		frame = frame.Intersect(rect)
+		for y := frame.Min.Y; y < frame.Max.Y; y++ {
+			fillPix(pix[y*stride+frame.Min.X:y*stride+frame.Max.X], light)
+		}
+		logoWidth := image2.Bounds().Dx()
+		at := logo.Min
+		logo = logo.Intersect(image.Rectangle{Max: image2.Bounds().Size()}.Add(at)).Intersect(rect)
+		for y := logo.Min.Y; y < logo.Max.Y; y++ {
+			i := (y-at.Y)*logoWidth + logo.Min.X - at.X
+			copy(pix[y*stride+logo.Min.X:y*stride+logo.Max.X], logoPix[i:])
+		}
+	}
+	return image1
+}
+
+//Палитра из светлого, темного и цветов картинки с индексами пикселей картинки по строкам.
+//nil, если цветов больше 256. Цвета приводятся к NRGBA так же, как при рисовании в draw.Draw:
+//пиксели NRGBA копируются без изменений, остальные через цвет с предумноженной альфой
+func logoPalette(logo image.Image, p Style) (color.Palette, []uint8) {
+	l := color.NRGBAModel.Convert(p.Light).(color.NRGBA)
+	d := color.NRGBAModel.Convert(p.Dark).(color.NRGBA)
+	pal := color.Palette{l, d}
+	index := map[color.NRGBA]uint8{l: 0, d: 1}
+	nrgba, _ := logo.(*image.NRGBA)
+	b := logo.Bounds()
+	pix := make([]uint8, 0, b.Dx()*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			var c color.NRGBA
+			if nrgba != nil {
+				c = nrgba.NRGBAAt(x, y)
+			} else {
+				r, g, bl, a := logo.At(x, y).RGBA()
+				c = color.NRGBAModel.Convert(color.RGBA64{uint16(r), uint16(g), uint16(bl), uint16(a)}).(color.NRGBA)
+			}
+			i, ok := index[c]
+			if !ok {
+				if len(pal) == 256 {
+					return nil, nil
 				}
+				i = uint8(len(pal))
+				index[c] = i
+				pal = append(pal, c)
 			}
+			pix = append(pix, i)
 		}
 	}
-	return image1
+	return pal, pix
+}
+
+//Заполнение пикселей повторением пикселя c
+func fillPix(pix, c []uint8) {
+	if len(pix) == 0 {
+		return
+	}
+	n := copy(pix, c)
+	for n < len(pix) {
+		n += copy(pix[n:], pix[:n])
+	}
 }
 
 //Вывод модели гифки
-func paintGIF(size, maxSizeImg int, dataImg *[][]byte, image2 *gif.GIF) *gif.GIF {
+func paintGIF(size, maxSizeImg int, dataImg *[][]byte, image2 *gif.GIF, p Style) *gif.GIF {
 	image1 := &gif.GIF{
 		Delay:     image2.Delay,
 		LoopCount: image2.LoopCount,
 	}
 	for _, img := range image2.Image {
-		frame := paintImage(size, maxSizeImg, dataImg, img)
-		pall := image.NewPaletted(frame.Rect, palette.Plan9)
-		draw.FloydSteinberg.Draw(pall, frame.Rect, frame, image.Point{})
+		frame := paintImage(size, maxSizeImg, dataImg, img, p)
+		pall := image.NewPaletted(frame.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(pall, frame.Bounds(), frame, image.Point{})
 
 		image1.Image = append(image1.Image, pall)
 	}
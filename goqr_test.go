@@ -0,0 +1,105 @@
+package goqr
+
+import (
+	"strings"
+	"testing"
+)
+
+//Строки матрицы символа: # темный модуль, . светлый
+func codeRows(code *Code) []string {
+	rows := make([]string, code.Height())
+	for y := range rows {
+		var row strings.Builder
+		for x := 0; x < code.Width(); x++ {
+			if code.Module(x, y) {
+				row.WriteByte('#')
+			} else {
+				row.WriteByte('.')
+			}
+		}
+		rows[y] = row.String()
+	}
+	return rows
+}
+
+//Сравнение матрицы символа с эталоном построчно
+func checkRows(t *testing.T, code *Code, want []string) {
+	t.Helper()
+	got := codeRows(code)
+	if len(got) != len(want) {
+		t.Fatalf("height %d, want %d", len(got), len(want))
+	}
+	for y := range want {
+		if got[y] != want[y] {
+			t.Errorf("row %d\n got %s\nwant %s", y, got[y], want[y])
+		}
+	}
+}
+
+//Пример 1-M из приложения стандарта: цифры 01234567 с маской 010
+func TestEncodeGolden(t *testing.T) {
+	code, err := Encode("01234567", Options{Level: LevelM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.Version() != 1 || code.Level() != LevelM || code.Mask() != Mask2 {
+		t.Fatalf("version %d level %d mask %d, want 1 %d %d", code.Version(), code.Level(), code.Mask(), LevelM, Mask2)
+	}
+	checkRows(t, code, []string{
+		"#######..#.##.#######",
+		"#.....#..####.#.....#",
+		"#.###.#.#.....#.###.#",
+		"#.###.#.##....#.###.#",
+		"#.###.#.#.###.#.###.#",
+		"#.....#.#...#.#.....#",
+		"#######.#.#.#.#######",
+		"........#..##........",
+		"#.#####..#..#.#####..",
+		"...#.#.##.#.#..#.##..",
+		"..#...##.#.#.#..#####",
+		"....#....#.....####..",
+		"...######..#.#..#....",
+		"........#.#####..##..",
+		"#######..##.#.##.....",
+		"#.....#.#.#####...#.#",
+		"#.###.#.#...#..#.##..",
+		"#.###.#.##..#..#.....",
+		"#.###.#.#.##.#..#.#..",
+		"#.....#........##.##.",
+		"#######.####.#..#.#..",
+	})
+}
+
+//Размер символа и наличие информации о версии для всех 40 версий
+func TestEncodeVersions(t *testing.T) {
+	for version := 1; version <= 40; version++ {
+		code, err := Encode("1", Options{MinVersion: version})
+		if err != nil {
+			t.Fatalf("version %d: %v", version, err)
+		}
+		if size := 17 + 4*version; code.Version() != version || code.Size() != size {
+			t.Fatalf("version %d size %d, want %d %d", code.Version(), code.Size(), version, size)
+		}
+		if got := code.Pattern(code.Size()-11, 0) == PatternVersion; got != (version >= 7) {
+			t.Errorf("version %d: version info %v", version, got)
+		}
+	}
+}
+
+//Информация о версии BCH(18,6) по таблице стандарта
+func TestVersionCode(t *testing.T) {
+	for _, tt := range []struct {
+		version, code int
+	}{
+		{7, 0x07c94},
+		{8, 0x085bc},
+		{9, 0x09a99},
+		{10, 0x0a4d3},
+		{20, 0x149a6},
+		{40, 0x28c69},
+	} {
+		if got := versionCode(tt.version); got != tt.code {
+			t.Errorf("versionCode(%d) = %#05x, want %#05x", tt.version, got, tt.code)
+		}
+	}
+}
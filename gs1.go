@@ -0,0 +1,165 @@
+package goqr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//FNC1 режим данных по стандарту GS1 или отраслевому стандарту
+type FNC1 byte
+
+const (
+	//FNC1None обычные данные
+	FNC1None FNC1 = iota
+	//FNC1First данные GS1 с прикладными идентификаторами
+	FNC1First
+	//FNC1Second данные отраслевого стандарта с индикатором приложения
+	FNC1Second
+)
+
+//Режимы FNC1
+const (
+	modeFNC1First  = 0x5
+	modeFNC1Second = 0x9
+)
+
+//Разделитель групп GS1
+const groupSeparator = 0x1d
+
+//Экранирование в буквенно-цифровом режиме FNC1 и его снятие
+var (
+	fnc1Replacer  = strings.NewReplacer("%", "%%", string(rune(groupSeparator)), "%")
+	fnc1Unescaper = strings.NewReplacer("%%", "%", "%", string(rune(groupSeparator)))
+)
+
+//Длина элемента с идентификатором для идентификаторов фиксированной длины по первым двум цифрам
+var lenFixedAI = map[string]int{
+	"00": 20, "01": 16, "02": 16, "03": 16, "04": 18,
+	"11": 8, "12": 8, "13": 8, "14": 8, "15": 8, "16": 8, "17": 8, "18": 8, "19": 8,
+	"20": 4,
+	"31": 10, "32": 10, "33": 10, "34": 10, "35": 10, "36": 10,
+	"41": 16,
+}
+
+//Символы, допустимые в значениях GS1
+const gs1Table = "!\"%&'()*+,-./0123456789:;<=>?ABCDEFGHIJKLMNOPQRSTUVWXYZ_abcdefghijklmnopqrstuvwxyz"
+
+//Значение индикатора приложения: две цифры или латинская буква плюс 100
+func appIndicator(app string) (int, error) {
+	switch {
+	case len(app) == 2 && isDigits(app):
+		return int(app[0]-'0')*10 + int(app[1]-'0'), nil
+	case len(app) == 1 && (app[0] >= 'a' && app[0] <= 'z' || app[0] >= 'A' && app[0] <= 'Z'):
+		return int(app[0]) + 100, nil
+	}
+	return 0, errors.New("app indicator wrong")
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+//GS1 построитель строки прикладных идентификаторов GS1 для генерации с FNC1First
+type GS1 struct {
+	elements []string
+	err      error
+}
+
+//NewGS1 создает пустую строку GS1
+func NewGS1() *GS1 {
+	return &GS1{}
+}
+
+//GTIN добавляет идентификатор 01, номер из 8, 12, 13 или 14 цифр с контрольной цифрой
+func (g *GS1) GTIN(gtin string) *GS1 {
+	if !isDigits(gtin) || (len(gtin) != 8 && len(gtin) != 12 && len(gtin) != 13 && len(gtin) != 14) {
+		return g.fail(errors.New("gtin wrong"))
+	}
+	gtin = strings.Repeat("0", 14-len(gtin)) + gtin
+	sum := 0
+	for i := 0; i < 13; i++ {
+		if i%2 == 0 {
+			sum += int(gtin[i]-'0') * 3
+		} else {
+			sum += int(gtin[i] - '0')
+		}
+	}
+	if (10-sum%10)%10 != int(gtin[13]-'0') {
+		return g.fail(errors.New("gtin check digit wrong"))
+	}
+	return g.AI("01", gtin)
+}
+
+//Batch добавляет идентификатор 10, номер партии до 20 символов
+func (g *GS1) Batch(batch string) *GS1 {
+	if len(batch) > 20 {
+		return g.fail(errors.New("batch too long"))
+	}
+	return g.AI("10", batch)
+}
+
+//Expiry добавляет идентификатор 17, срок годности ГГММДД
+func (g *GS1) Expiry(date time.Time) *GS1 {
+	return g.AI("17", date.Format("060102"))
+}
+
+//Serial добавляет идентификатор 21, серийный номер до 20 символов
+func (g *GS1) Serial(serial string) *GS1 {
+	if len(serial) > 20 {
+		return g.fail(errors.New("serial too long"))
+	}
+	return g.AI("21", serial)
+}
+
+//AI добавляет произвольный прикладной идентификатор из 2-4 цифр со значением
+func (g *GS1) AI(ai, value string) *GS1 {
+	if !isDigits(ai) || len(ai) < 2 || len(ai) > 4 {
+		return g.fail(fmt.Errorf("ai %q wrong", ai))
+	}
+	if value == "" || len(value) > 90 {
+		return g.fail(fmt.Errorf("ai %s value length wrong", ai))
+	}
+	for i := 0; i < len(value); i++ {
+		if strings.IndexByte(gs1Table, value[i]) < 0 {
+			return g.fail(fmt.Errorf("ai %s value %q wrong", ai, value))
+		}
+	}
+	if l, ok := lenFixedAI[ai[:2]]; ok && len(ai)+len(value) != l {
+		return g.fail(fmt.Errorf("ai %s must be %d symbols", ai, l))
+	}
+	g.elements = append(g.elements, ai+value)
+	return g
+}
+
+//Build собирает строку с разделителями групп после элементов переменной длины
+func (g *GS1) Build() (string, error) {
+	if g.err != nil {
+		return "", g.err
+	}
+	if len(g.elements) == 0 {
+		return "", errors.New("gs1 is empty")
+	}
+	var b strings.Builder
+	for i, el := range g.elements {
+		b.WriteString(el)
+		if _, ok := lenFixedAI[el[:2]]; !ok && i < len(g.elements)-1 {
+			b.WriteByte(groupSeparator)
+		}
+	}
+	return b.String(), nil
+}
+
+//Запоминает первую ошибку построения
+func (g *GS1) fail(err error) *GS1 {
+	if g.err == nil {
+		g.err = err
+	}
+	return g
+}
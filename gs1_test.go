@@ -0,0 +1,130 @@
+package goqr
+
+import (
+	"testing"
+	"time"
+)
+
+//Строка GS1: разделитель групп только после элементов переменной длины перед следующим элементом
+func TestGS1Build(t *testing.T) {
+	gs := string(rune(groupSeparator))
+	for _, tt := range []struct {
+		name string
+		gs1  *GS1
+		want string
+	}{
+		{"gtin-13", NewGS1().GTIN("4006381333931"), "0104006381333931"},
+		{"gtin-8", NewGS1().GTIN("96385074"), "0100000096385074"},
+		{"fixed then variable", NewGS1().GTIN("4006381333931").Batch("ABC"), "0104006381333931" + "10ABC"},
+		{"variable then variable", NewGS1().Batch("ABC").Serial("42"), "10ABC" + gs + "2142"},
+		{"variable then fixed", NewGS1().Batch("ABC").Expiry(time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)), "10ABC" + gs + "17250131"},
+		{"fixed in the middle", NewGS1().Serial("7").GTIN("04006381333931").AI("3103", "000150"), "217" + gs + "0104006381333931" + "3103000150"},
+	} {
+		got, err := tt.gs1.Build()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+//Ошибки построителя GS1 запоминаются до Build
+func TestGS1Errors(t *testing.T) {
+	for name, gs1 := range map[string]*GS1{
+		"gtin check digit": NewGS1().GTIN("4006381333932"),
+		"gtin length":      NewGS1().GTIN("400638133393"),
+		"gtin letters":     NewGS1().GTIN("40063813339A1"),
+		"fixed ai length":  NewGS1().AI("01", "123"),
+		"ai letters":       NewGS1().AI("1A", "123"),
+		"value symbol":     NewGS1().AI("10", "A B"),
+		"batch too long":   NewGS1().Batch("123456789012345678901"),
+		"empty":            NewGS1(),
+		"first error kept": NewGS1().GTIN("1").Batch("ABC"),
+	} {
+		if _, err := gs1.Build(); err == nil {
+			t.Errorf("%s: want error", name)
+		}
+	}
+}
+
+//Индикатор приложения FNC1Second: две цифры или латинская буква плюс 100
+func TestAppIndicator(t *testing.T) {
+	for _, tt := range []struct {
+		app   string
+		value int
+		ok    bool
+	}{
+		{"37", 37, true},
+		{"00", 0, true},
+		{"a", 'a' + 100, true},
+		{"Z", 'Z' + 100, true},
+		{"", 0, false},
+		{"123", 0, false},
+		{"1", 0, false},
+		{"я", 0, false},
+	} {
+		value, err := appIndicator(tt.app)
+		if (err == nil) != tt.ok || value != tt.value {
+			t.Errorf("appIndicator(%q) = %d, %v, want %d ok %v", tt.app, value, err, tt.value, tt.ok)
+		}
+	}
+}
+
+//Экранирование в буквенно-цифровом режиме FNC1: % пишется как %%, разделитель групп как %
+func TestSplitFNC1(t *testing.T) {
+	gs := string(rune(groupSeparator))
+	for _, tt := range []struct {
+		content string
+		opt     Options
+		modes   []int
+		parts   []string
+	}{
+		{"10ABC%1" + gs + "21XYZ", Options{FNC1: FNC1First}, []int{modeFNC1First, modeAlphanum}, []string{"", "10ABC%%1%21XYZ"}},
+		{"0104006381333931" + "1012%A" + gs + "21B", Options{FNC1: FNC1First}, []int{modeFNC1First, modeNumeric, modeAlphanum}, []string{"", "01040063813339311012", "%%A%21B"}},
+		{"AB%C", Options{FNC1: FNC1Second, AppIndicator: "a"}, []int{modeFNC1Second, modeAlphanum}, []string{"", "AB%%C"}},
+	} {
+		segments, _ := splitCharset(tt.content, qrHeader(0), tt.opt)
+		if len(segments) != len(tt.modes) {
+			t.Errorf("%q: %d segments, want %d", tt.content, len(segments), len(tt.modes))
+			continue
+		}
+		for i, seg := range segments {
+			if seg.mode != tt.modes[i] || seg.content != tt.parts[i] {
+				t.Errorf("%q: segment %d mode %d %q, want %d %q", tt.content, i, seg.mode, seg.content, tt.modes[i], tt.parts[i])
+			}
+		}
+	}
+}
+
+//Заголовок FNC1Second: режим 1001 и индикатор приложения в 8 битах
+func TestFNC1SecondBits(t *testing.T) {
+	segments, _ := splitCharset("AB", qrHeader(0), Options{FNC1: FNC1Second, AppIndicator: "a"})
+	data := addServicesData(segments, qrHeader(0), 128)
+	head := 0
+	for i := 0; i < 12; i++ {
+		head = head<<1 | int(data.bit(i))
+	}
+	if want := modeFNC1Second<<8 | int('a'+100); head != want {
+		t.Errorf("header %#03x, want %#03x", head, want)
+	}
+}
+
+//Порядок заголовков: структурированное объединение, затем ECI, затем FNC1
+func TestIndicatorOrder(t *testing.T) {
+	opt := Options{Level: LevelM, MaxVersion: 1, Charset: CharsetWindows1251, FNC1: FNC1First}
+	_, parts, err := splitAppend("10Партия"+string(rune(groupSeparator))+"21СЕРИЙНЫЙНОМЕР0123456789", opt, levelTables[LevelM].maxData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) < 2 {
+		t.Fatalf("parts %d, want at least 2", len(parts))
+	}
+	for i, part := range parts {
+		if len(part) < 3 || part[0].mode != modeAppend || part[1].mode != modeECI || part[1].value != 22 || part[2].mode != modeFNC1First {
+			t.Errorf("part %d segments %v, want append, eci 22, fnc1 first", i, part)
+		}
+	}
+}
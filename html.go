@@ -0,0 +1,88 @@
+package goqr
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+)
+
+const (
+	//FormatHTML фрагмент html из таблицы с inline стилями, соседние модули одного цвета объединяются через colspan
+	FormatHTML Format = "html"
+	//FormatHTMLGrid фрагмент html из div в css grid с inline стилями, div на каждый модуль
+	FormatHTMLGrid Format = "html-grid"
+)
+
+func init() {
+	RegisterRenderer(FormatHTML, RendererFunc(renderHTML), ".html", ".htm")
+	RegisterRenderer(FormatHTMLGrid, RendererFunc(renderHTMLGrid))
+}
+
+//Вывод символа таблицей, тихая зона тоже ячейками: почтовые клиенты часто не учитывают padding таблиц
+func renderHTML(w io.Writer, code *Code, style Style) error {
+	q, px := style.QuietZone, style.ModuleSize
+	dark, light := htmlColor(style.Dark), htmlColor(style.Light)
+	width := code.Width() + 2*q
+
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "<table cellpadding=\"0\" cellspacing=\"0\" border=\"0\" bgcolor=\"%s\" style=\"border-collapse:collapse;border-spacing:0;table-layout:fixed;background:%s;width:%dpx\">\n",
+		light, light, width*px)
+	//Явная ширина столбцов, иначе объединенные ячейки растягивают столбцы неравномерно
+	buf.WriteString("<colgroup>")
+	for x := 0; x < width; x++ {
+		fmt.Fprintf(buf, "<col width=\"%d\" style=\"width:%dpx\">", px, px)
+	}
+	buf.WriteString("</colgroup>\n")
+	for y := -q; y < code.Height()+q; y++ {
+		buf.WriteString("<tr>")
+		//Отрезки модулей одного цвета
+		for x := -q; x < code.Width()+q; {
+			n := 1
+			for x+n < code.Width()+q && code.Module(x+n, y) == code.Module(x, y) {
+				n++
+			}
+			c := light
+			if code.Module(x, y) {
+				c = dark
+			}
+			span := ""
+			if n > 1 {
+				span = fmt.Sprintf(" colspan=\"%d\"", n)
+			}
+			fmt.Fprintf(buf, "<td%s bgcolor=\"%s\" style=\"padding:0;width:%dpx;height:%dpx;background:%s\"></td>", span, c, n*px, px, c)
+			x += n
+		}
+		buf.WriteString("</tr>\n")
+	}
+	buf.WriteString("</table>\n")
+	return buf.Flush()
+}
+
+//Вывод символа сеткой div, тихая зона отступом контейнера
+func renderHTMLGrid(w io.Writer, code *Code, style Style) error {
+	q, px := style.QuietZone, style.ModuleSize
+
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "<div style=\"display:inline-grid;grid-template-columns:repeat(%d,%dpx);grid-auto-rows:%dpx;padding:%dpx;background:%s\">\n",
+		code.Width(), px, px, q*px, htmlColor(style.Light))
+	dark := fmt.Sprintf("<div style=\"background:%s\"></div>", htmlColor(style.Dark))
+	for y := 0; y < code.Height(); y++ {
+		for x := 0; x < code.Width(); x++ {
+			if code.Module(x, y) {
+				buf.WriteString(dark)
+			} else {
+				buf.WriteString("<div></div>")
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("</div>\n")
+	return buf.Flush()
+}
+
+//Цвет #rrggbb, прозрачность не учитывается
+func htmlColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
@@ -0,0 +1,110 @@
+package goqr
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var (
+	htmlRow  = regexp.MustCompile(`<tr>(.*?)</tr>`)
+	htmlCell = regexp.MustCompile(`<td(?: colspan="(\d+)")? bgcolor="(#[0-9a-f]{6})" style="padding:0;width:(\d+)px;height:3px;background:(#[0-9a-f]{6})"></td>`)
+)
+
+//Отрезки colspan в каждой строке таблицы дают ширину символа с тихой зоной и повторяют модули
+func TestRenderHTML(t *testing.T) {
+	code, err := Encode("HELLO WORLD", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	style := Style{QuietZone: 2, ModuleSize: 3, Dark: color.RGBA{0, 0, 0x80, 0xff}, Light: color.White}
+	var buf bytes.Buffer
+	if err := renderHTML(&buf, code, style); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	width := code.Width() + 2*style.QuietZone
+	if n := strings.Count(html, `<col width="3" style="width:3px">`); n != width {
+		t.Errorf("cols %d, want %d", n, width)
+	}
+	if head := fmt.Sprintf("width:%dpx\">", width*3); !strings.Contains(html, head) {
+		t.Errorf("table without %s", head)
+	}
+
+	rows := htmlRow.FindAllStringSubmatch(html, -1)
+	if len(rows) != code.Height()+2*style.QuietZone {
+		t.Fatalf("rows %d, want %d", len(rows), code.Height()+2*style.QuietZone)
+	}
+	cells := 0
+	for i, row := range rows {
+		y := i - style.QuietZone
+		x := -style.QuietZone
+		prev := ""
+		for _, cell := range htmlCell.FindAllStringSubmatch(row[1], -1) {
+			n := 1
+			if cell[1] != "" {
+				n, _ = strconv.Atoi(cell[1])
+			}
+			if px, _ := strconv.Atoi(cell[3]); px != n*3 || cell[2] != cell[4] {
+				t.Fatalf("row %d: cell %s", y, cell[0])
+			}
+			if cell[2] == prev {
+				t.Fatalf("row %d: neighbour cells %s not merged", y, prev)
+			}
+			prev = cell[2]
+			for k := 0; k < n; k++ {
+				if dark := cell[2] == "#000080"; dark != code.Module(x+k, y) {
+					t.Fatalf("module %d,%d: dark %v", x+k, y, dark)
+				}
+			}
+			x += n
+			cells++
+		}
+		if x+style.QuietZone != width {
+			t.Fatalf("row %d: colspan sum %d, want %d", y, x+style.QuietZone, width)
+		}
+	}
+	if cells != strings.Count(html, "<td") || cells >= width*len(rows) {
+		t.Errorf("cells %d of %d parsed, want fewer than %d modules", cells, strings.Count(html, "<td"), width*len(rows))
+	}
+}
+
+//Сетка div из ширины на высоту ячеек, тихая зона отступом
+func TestRenderHTMLGrid(t *testing.T) {
+	code, err := Encode("HELLO WORLD", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	style := Style{QuietZone: 2, ModuleSize: 3, Dark: color.Black, Light: color.White}
+	var buf bytes.Buffer
+	if err := renderHTMLGrid(&buf, code, style); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	head := fmt.Sprintf(`<div style="display:inline-grid;grid-template-columns:repeat(%d,3px);grid-auto-rows:3px;padding:6px;background:#ffffff">`, code.Width())
+	if !strings.HasPrefix(html, head+"\n") {
+		t.Errorf("grid without %s", head)
+	}
+	dark := 0
+	for y := 0; y < code.Height(); y++ {
+		for x := 0; x < code.Width(); x++ {
+			if code.Module(x, y) {
+				dark++
+			}
+		}
+	}
+	if n := strings.Count(html, "<div></div>") + strings.Count(html, `<div style="background:#000000"></div>`); n != code.Width()*code.Height() {
+		t.Errorf("cells %d, want %d", n, code.Width()*code.Height())
+	}
+	if n := strings.Count(html, `<div style="background:#000000"></div>`); n != dark {
+		t.Errorf("dark cells %d, want %d", n, dark)
+	}
+	lines := strings.Split(strings.TrimSuffix(html, "</div>\n"), "\n")
+	if len(lines) != code.Height()+2 {
+		t.Errorf("lines %d, want container and %d rows", len(lines), code.Height())
+	}
+}
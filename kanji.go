@@ -0,0 +1,53 @@
+package goqr
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+//Кодировщик отдельных символов в Shift JIS, создается один на проход по строке
+type sjisEncoder struct {
+	enc transform.Transformer
+	//Символ в UTF-8 и его код
+	src, dst [utf8.UTFMax]byte
+}
+
+//Новый кодировщик с одним преобразователем на все символы
+func newSJISEncoder() *sjisEncoder {
+	return &sjisEncoder{enc: japanese.ShiftJIS.NewEncoder()}
+}
+
+//Код символа в Shift JIS, если он помещается в режим кандзи.
+//Кириллица и греческий тоже есть в JIS X 0208, но они остаются в байтовом режиме,
+//поэтому берутся только японские символы начиная с U+3000
+func (e *sjisEncoder) code(r rune) (code int, ok bool) {
+	if r < 0x3000 {
+		return 0, false
+	}
+	e.enc.Reset()
+	n, _, err := e.enc.Transform(e.dst[:], e.src[:utf8.EncodeRune(e.src[:], r)], true)
+	if err != nil || n != 2 {
+		return 0, false
+	}
+	code = int(e.dst[0])<<8 | int(e.dst[1])
+	if (code >= 0x8140 && code <= 0x9ffc) || (code >= 0xe040 && code <= 0xebbf) {
+		return code, true
+	}
+	return 0, false
+}
+
+//Запись строки в режиме кандзи в двоичную последовательность по 13 бит на символ
+func kanjiToBit(buf *bitBuffer, content string) {
+	sjis := newSJISEncoder()
+	for _, r := range content {
+		code, _ := sjis.code(r)
+		if code <= 0x9ffc {
+			code -= 0x8140
+		} else {
+			code -= 0xc140
+		}
+		buf.put((code>>8)*0xc0+(code&0xff), 13)
+	}
+}
@@ -0,0 +1,197 @@
+package goqr
+
+//Mask шаблон маски
+type Mask byte
+
+const (
+	//MaskAuto выбор маски по наименьшему штрафу
+	MaskAuto Mask = iota
+	//Mask0 (y + x) mod 2 = 0
+	Mask0
+	//Mask1 y mod 2 = 0
+	Mask1
+	//Mask2 x mod 3 = 0
+	Mask2
+	//Mask3 (y + x) mod 3 = 0
+	Mask3
+	//Mask4 (y / 2 + x / 3) mod 2 = 0
+	Mask4
+	//Mask5 (y * x) mod 2 + (y * x) mod 3 = 0
+	Mask5
+	//Mask6 ((y * x) mod 2 + (y * x) mod 3) mod 2 = 0
+	Mask6
+	//Mask7 ((y + x) mod 2 + (y * x) mod 3) mod 2 = 0
+	Mask7
+)
+
+//Полином BCH(15,5) и маска для информации о формате
+const (
+	polinomFormat = 0x537
+	maskFormat    = 0x5412
+)
+
+//Веса правил штрафа
+const (
+	penaltyN1 = 3
+	penaltyN2 = 3
+	penaltyN3 = 40
+	penaltyN4 = 10
+)
+
+var maskFunc = []func(x, y int) bool{
+	func(x, y int) bool { return (y+x)%2 == 0 },
+	func(x, y int) bool { return y%2 == 0 },
+	func(x, y int) bool { return x%3 == 0 },
+	func(x, y int) bool { return (y+x)%3 == 0 },
+	func(x, y int) bool { return (y/2+x/3)%2 == 0 },
+	func(x, y int) bool { return (y*x)%2+(y*x)%3 == 0 },
+	func(x, y int) bool { return ((y*x)%2+(y*x)%3)%2 == 0 },
+	func(x, y int) bool { return ((y+x)%2+(y*x)%3)%2 == 0 },
+}
+
+//Вычисление информации о формате BCH(15,5)
+func formatCode(levelBits, mask int) int {
+	return formatBCH(levelBits<<3|mask) ^ maskFormat
+}
+
+//Код BCH(15,5) для пяти бит формата
+func formatBCH(data int) int {
+	code := data << 10
+	for i := 14; i > 9; i-- {
+		if code&(1<<i) != 0 {
+			code ^= polinomFormat << (i - 10)
+		}
+	}
+	return data<<10 | code
+}
+
+//Выбор и наложение маски
+func chooseMask(img *[][]byte, levelBits int, mask Mask) int {
+	best := int(mask) - int(Mask0)
+	if mask == MaskAuto {
+		minPenalty := -1
+		for i := range maskFunc {
+			applyMask(img, i)
+			maskInfo(img, formatCode(levelBits, i))
+			if p := penalty(img); minPenalty < 0 || p < minPenalty {
+				minPenalty = p
+				best = i
+			}
+			applyMask(img, i)
+		}
+	}
+	applyMask(img, best)
+	maskInfo(img, formatCode(levelBits, best))
+	return best
+}
+
+//Наложение маски на модули данных, повторное наложение снимает маску
+func applyMask(img *[][]byte, mask int) {
+	f := maskFunc[mask]
+	for y := range *img {
+		for x := range (*img)[y] {
+			if (*img)[y][x] < search0 && f(x, y) {
+				(*img)[y][x] ^= 1
+			}
+		}
+	}
+}
+
+//Подсчет штрафа по четырем правилам
+func penalty(img *[][]byte) (score int) {
+	size := len(*img)
+	dark := func(x, y int) bool {
+		return (*img)[y][x]%2 == 1
+	}
+	light := func(x, y int, horizontal bool) bool {
+		if x < 0 || y < 0 || x >= size || y >= size {
+			return true
+		}
+		if horizontal {
+			return !dark(x, y)
+		}
+		return !dark(y, x)
+	}
+
+	var countDark int
+	for i := 0; i < size; i++ {
+		//Правило 1: пять и более модулей одного цвета подряд
+		for _, horizontal := range []bool{true, false} {
+			run := 1
+			for j := 1; j < size; j++ {
+				var a, b bool
+				if horizontal {
+					a, b = dark(j, i), dark(j-1, i)
+				} else {
+					a, b = dark(i, j), dark(i, j-1)
+				}
+				if a == b {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += penaltyN1 + run - 5
+				}
+				run = 1
+			}
+			if run >= 5 {
+				score += penaltyN1 + run - 5
+			}
+		}
+
+		for j := 0; j < size; j++ {
+			if dark(j, i) {
+				countDark++
+			}
+			//Правило 2: блоки 2x2 одного цвета
+			if i < size-1 && j < size-1 {
+				d := dark(j, i)
+				if d == dark(j+1, i) && d == dark(j, i+1) && d == dark(j+1, i+1) {
+					score += penaltyN2
+				}
+			}
+			//Правило 3: шаблон 1:1:3:1:1 со светлой полосой в четыре модуля
+			for _, horizontal := range []bool{true, false} {
+				x, y := j, i
+				if !horizontal {
+					x, y = i, j
+				}
+				if j+6 >= size || !dark(x, y) {
+					continue
+				}
+				pattern := true
+				for k, v := range []bool{true, false, true, true, true, false, true} {
+					if !horizontal {
+						if dark(x, y+k) != v {
+							pattern = false
+							break
+						}
+					} else if dark(x+k, y) != v {
+						pattern = false
+						break
+					}
+				}
+				if !pattern {
+					continue
+				}
+				before, after := true, true
+				for k := 1; k < 5; k++ {
+					before = before && light(j-k, i, horizontal)
+					after = after && light(j+6+k, i, horizontal)
+				}
+				if before || after {
+					score += penaltyN3
+				}
+			}
+		}
+	}
+
+	//Правило 4: отклонение доли темных модулей от половины
+	total := size * size
+	variance := countDark*2 - total
+	if variance < 0 {
+		variance = -variance
+	}
+	score += variance * 10 / total * penaltyN4
+	return
+}
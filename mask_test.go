@@ -0,0 +1,77 @@
+package goqr
+
+import "testing"
+
+//Информация о формате по таблице стандарта для всех уровней и масок
+func TestFormatCode(t *testing.T) {
+	want := map[int][]int{
+		levelBitsL: {0x77c4, 0x72f3, 0x7daa, 0x789d, 0x662f, 0x6318, 0x6c41, 0x6976},
+		levelBitsM: {0x5412, 0x5125, 0x5e7c, 0x5b4b, 0x45f9, 0x40ce, 0x4f97, 0x4aa0},
+		levelBitsQ: {0x355f, 0x3068, 0x3f31, 0x3a06, 0x24b4, 0x2183, 0x2eda, 0x2bed},
+		levelBitsH: {0x1689, 0x13be, 0x1ce7, 0x19d0, 0x0762, 0x0255, 0x0d0c, 0x083b},
+	}
+	for levelBits, codes := range want {
+		for mask, code := range codes {
+			if got := formatCode(levelBits, mask); got != code {
+				t.Errorf("formatCode(%d, %d) = %#04x, want %#04x", levelBits, mask, got, code)
+			}
+		}
+	}
+}
+
+//Штраф светлого квадрата и шахматной доски
+func TestPenalty(t *testing.T) {
+	square := func(size int, dark func(x, y int) bool) [][]byte {
+		img := make([][]byte, size)
+		for y := range img {
+			img[y] = make([]byte, size)
+			for x := range img[y] {
+				if dark(x, y) {
+					img[y][x] = 1
+				}
+			}
+		}
+		return img
+	}
+	for _, tt := range []struct {
+		name  string
+		img   [][]byte
+		score int
+	}{
+		//Правило 1: 10 полос по 3, правило 2: 16 блоков по 3, правило 4: отклонение 50%
+		{"light", square(5, func(x, y int) bool { return false }), 30 + 48 + 100},
+		{"checker", square(6, func(x, y int) bool { return (x+y)%2 == 0 }), 0},
+	} {
+		if got := penalty(&tt.img); got != tt.score {
+			t.Errorf("%s: penalty %d, want %d", tt.name, got, tt.score)
+		}
+	}
+}
+
+//Заданная маска накладывается и записывается в информацию о формате
+func TestEncodeMask(t *testing.T) {
+	for mask := Mask0; mask <= Mask7; mask++ {
+		code, err := Encode("01234567", Options{Level: LevelM, Mask: mask})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code.Mask() != mask {
+			t.Fatalf("mask %d, want %d", code.Mask(), mask)
+		}
+		//Информация о формате возле левого верхнего поискового узора, старшим битом вниз по столбцу 8
+		format := 0
+		for i, y := range []int{0, 1, 2, 3, 4, 5, 7, 8} {
+			if code.Module(8, y) {
+				format |= 1 << i
+			}
+		}
+		for i, x := range []int{7, 5, 4, 3, 2, 1, 0} {
+			if code.Module(x, 8) {
+				format |= 1 << (8 + i)
+			}
+		}
+		if want := formatCode(levelBitsM, int(mask-Mask0)); format != want {
+			t.Errorf("mask %d: format info %#04x, want %#04x", mask, format, want)
+		}
+	}
+}
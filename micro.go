@@ -0,0 +1,190 @@
+package goqr
+
+//Маска информации о формате micro qr
+const maskMicroFormat = 0x4445
+
+//Символы micro qr по номеру: M1, M2-L, M2-M, M3-L, M3-M, M4-L, M4-M, M4-Q
+var (
+	microVersion = []int{0, 1, 1, 2, 2, 3, 3, 3}
+	microLevel   = []Level{LevelL, LevelL, LevelM, LevelL, LevelM, LevelL, LevelM, LevelQ}
+	//Количество бит данных, у M1 и M3 последний байт данных четырехбитный
+	microMaxData = []int{20, 40, 32, 84, 68, 128, 112, 80}
+	//Количество байт коррекции
+	microByteCorect = []int{2, 5, 6, 6, 8, 8, 10, 14}
+)
+
+//Размер micro qr по версии M1–M4
+var microBlocks = []int{11, 13, 15, 17}
+
+//Длина счетчика символов micro qr по версии, 0 если режим недоступен
+var lenCountMicro = map[int][]int{
+	modeNumeric:  {3, 4, 5, 6},
+	modeAlphanum: {0, 3, 4, 5},
+	modeByte:     {0, 0, 4, 5},
+	modeKanji:    {0, 0, 3, 4},
+}
+
+//Индикаторы режимов micro qr
+var codeModeMicro = map[int]int{modeNumeric: 0, modeAlphanum: 1, modeByte: 2, modeKanji: 3}
+
+//Маски micro qr 00–11 среди масок qr
+var maskMicro = []int{1, 4, 6, 7}
+
+//EncodeMicro кодирует строку в матрицу micro qr M1–M4 без вывода изображения.
+//LevelAuto выбирает наименьший символ с наибольшей коррекцией, LevelH недоступен.
+//Маски Mask0–Mask3 соответствуют шаблонам micro qr 00–11
+func EncodeMicro(content string, opt Options) (*Code, error) {
+	if _, err := newConfig(append(opt.options(), WithMicro())); err != nil {
+		return nil, err
+	}
+
+	//Выбор символа и разбиение строки на сегменты
+	symbol, segments, _, err := howToMicro(content, opt.Level)
+	if err != nil {
+		return nil, err
+	}
+	dataImg, mask := buildMicro(segments, symbol, opt.Mask)
+	return &Code{modules: dataImg, version: microVersion[symbol], level: microLevel[symbol], mask: Mask0 + Mask(mask)}, nil
+}
+
+//Заголовки сегментов micro qr для версии
+func microHeader(version int) symbolHeader {
+	head := symbolHeader{lenMode: version, lenCount: map[int]int{}, codeMode: codeModeMicro, lenTerminator: 3 + 2*version}
+	for mode, lens := range lenCountMicro {
+		if lens[version] > 0 {
+			head.lenCount[mode] = lens[version]
+		}
+	}
+	return head
+}
+
+//Выбор наименьшего символа micro qr, при LevelAuto с наибольшей коррекцией
+func howToMicro(content string, level Level) (symbol int, segments []segment, length int, err error) {
+	for version := range microBlocks {
+		segments, length = splitCharset(content, microHeader(version), Options{})
+		if segments == nil {
+			continue
+		}
+		symbol = -1
+		for i, v := range microVersion {
+			if v != version || length > microMaxData[i] {
+				continue
+			}
+			if level == LevelAuto || microLevel[i] == level {
+				symbol = i
+			}
+		}
+		if symbol >= 0 {
+			return symbol, segments, length, nil
+		}
+	}
+	return 0, nil, 0, errOversize
+}
+
+//Построение модулей micro qr по сегментам, возвращает модули и выбранную маску
+func buildMicro(segments []segment, symbol int, mask Mask) ([][]byte, int) {
+	version, maxData := microVersion[symbol], microMaxData[symbol]
+	//Запись сегментов в начало последовательности
+	head := microHeader(version)
+	data := addServicesData(segments, head, maxData)
+	//Дозаполнение терминатором и пустышками до необходимой длины
+	addVoidTerminator(maxData, head.lenTerminator, data)
+	//Один блок данных, четырехбитный байт занимает старшие биты
+	byteBlock := [][]byte{data.bytes()}
+	//Создание байт коррекции и запись их сразу за битами данных
+	countByteCorect, corectBlock, _ := buildCorectBlock(symbol, 1, &microByteCorect, &byteBlock)
+	data.putBytes(corectBlock[0][:countByteCorect])
+
+	//Рисование
+	size := microBlocks[version]
+	dataImg := make([][]byte, size)
+	for i := range dataImg {
+		dataImg[i] = make([]byte, size)
+	}
+	finder(&dataImg, 0, 0)
+	microSyncLine(&dataImg)
+	microInfo(&dataImg, 0)
+	writeBits(&dataImg, data, 0)
+	return dataImg, chooseMicroMask(&dataImg, symbol, mask)
+}
+
+//Дозаполнение терминатором заданной длины и пустышками до maxData бит, неполный последний байт остается нулевым
+func addVoidTerminator(maxData, lenTerminator int, data *bitBuffer) {
+	if data.len()+lenTerminator > maxData {
+		lenTerminator = maxData - data.len()
+	}
+	data.put(0, lenTerminator)
+	if rest := (8 - data.len()%8) % 8; data.len()+rest <= maxData {
+		data.put(0, rest)
+	}
+	pad := []int{0xec, 0x11}
+	for i := 0; data.len()+8 <= maxData/8*8; i++ {
+		data.put(pad[i%2], 8)
+	}
+	data.put(0, maxData-data.len())
+}
+
+//Рисование полос синхронизации micro qr по верхнему и левому краю
+func microSyncLine(img *[][]byte) {
+	for i := 8; i < len(*img); i++ {
+		x := sync0
+		if i%2 == 0 {
+			x = sync1
+		}
+		(*img)[0][i] = x
+		(*img)[i][0] = x
+	}
+}
+
+//Рисование информации о формате micro qr
+func microInfo(img *[][]byte, code int) {
+	for i := 0; i < 15; i++ {
+		x := mask0
+		if code&(1<<i) != 0 {
+			x = mask1
+		}
+		if i < 8 {
+			(*img)[i+1][8] = x
+		} else {
+			(*img)[8][15-i] = x
+		}
+	}
+}
+
+//Вычисление информации о формате micro qr
+func microFormatCode(symbol, mask int) int {
+	return formatBCH(symbol<<2|mask) ^ maskMicroFormat
+}
+
+//Выбор и наложение маски micro qr с наибольшим числом темных модулей на правом и нижнем краях
+func chooseMicroMask(img *[][]byte, symbol int, mask Mask) int {
+	best := int(mask) - int(Mask0)
+	if mask == MaskAuto {
+		maxScore := -1
+		for i, m := range maskMicro {
+			applyMask(img, m)
+			if s := microScore(img); s > maxScore {
+				maxScore = s
+				best = i
+			}
+			applyMask(img, m)
+		}
+	}
+	applyMask(img, maskMicro[best])
+	microInfo(img, microFormatCode(symbol, best))
+	return best
+}
+
+//Оценка маски micro qr по темным модулям правого столбца и нижней строки
+func microScore(img *[][]byte) int {
+	size := len(*img)
+	var sum1, sum2 int
+	for i := 1; i < size; i++ {
+		sum1 += int((*img)[i][size-1] % 2)
+		sum2 += int((*img)[size-1][i] % 2)
+	}
+	if sum1 > sum2 {
+		sum1, sum2 = sum2, sum1
+	}
+	return sum1*16 + sum2
+}
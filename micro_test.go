@@ -0,0 +1,67 @@
+package goqr
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+//M2-L с десятью цифрами: поисковый узор, полосы синхронизации, формат и маска 01
+func TestEncodeMicroGolden(t *testing.T) {
+	code, err := EncodeMicro("0123456789", Options{Level: LevelL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.Version() != 2 || code.Level() != LevelL || code.Mask() != Mask1 {
+		t.Fatalf("version %d level %d mask %d, want 2 %d %d", code.Version(), code.Level(), code.Mask(), LevelL, Mask1)
+	}
+	checkRows(t, code, []string{
+		"#######.#.#.#",
+		"#.....#.###.#",
+		"#.###.#...#.#",
+		"#.###.#...###",
+		"#.###.#.#....",
+		"#.....#.###.#",
+		"#######...###",
+		".........##..",
+		"##.#....#.#.#",
+		".#...##.#...#",
+		"####....####.",
+		".#.#....###..",
+		"##.#.#..#.###",
+	})
+}
+
+//Информация о формате micro qr: код BCH как в qr, но с маской 0x4445
+func TestMicroFormatCode(t *testing.T) {
+	for _, tt := range []struct {
+		symbol, mask, code int
+	}{
+		{0, 0, 0x4445},
+		{0, 1, 0x4172},
+		{2, 1, 0x72f3 ^ maskFormat ^ maskMicroFormat},
+		{7, 3, 0x2bed ^ maskFormat ^ maskMicroFormat},
+	} {
+		if got := microFormatCode(tt.symbol, tt.mask); got != tt.code {
+			t.Errorf("microFormatCode(%d, %d) = %#04x, want %#04x", tt.symbol, tt.mask, got, tt.code)
+		}
+	}
+}
+
+//Generate с WithMicro рисует micro qr и отклоняет недоступный уровень
+func TestGenerateMicro(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Generate(&buf, "0123456789", WithMicro(), WithLevel(LevelL), WithQuietZone(2)); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Dx(); size != 13+2*2 {
+		t.Errorf("image size %d, want %d", size, 13+2*2)
+	}
+	if err := Generate(&buf, "0123456789", WithMicro(), WithLevel(LevelH)); err == nil {
+		t.Error("LevelH in micro qr: want error")
+	}
+}
@@ -0,0 +1,292 @@
+package goqr
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+//Режимы кодирования
+const (
+	modeNumeric  = 0x1
+	modeAlphanum = 0x2
+	modeByte     = 0x4
+	modeKanji    = 0x8
+)
+
+//Символы буквенно-цифрового режима в порядке их кодов
+const alphanumTable = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+//Длина счетчика символов по группам версий 1-9, 10-26, 27-40
+var lenCountSymbol = map[int][]int{
+	modeNumeric:  {10, 12, 14},
+	modeAlphanum: {9, 11, 13},
+	modeByte:     {8, 16, 16},
+	modeKanji:    {8, 10, 12},
+}
+
+//Длины заголовков сегментов для типа символа
+type symbolHeader struct {
+	//Длина индикатора режима
+	lenMode int
+	//Длина счетчика символов по режимам, режимы без длины недоступны
+	lenCount map[int]int
+	//Индикаторы режимов, nil если индикатор совпадает с режимом
+	codeMode map[int]int
+	//Длина терминатора
+	lenTerminator int
+}
+
+//Заголовки сегментов qr для группы версий
+func qrHeader(group int) symbolHeader {
+	head := symbolHeader{lenMode: 4, lenCount: map[int]int{}, lenTerminator: 4}
+	for mode, lens := range lenCountSymbol {
+		head.lenCount[mode] = lens[group]
+	}
+	return head
+}
+
+//Индикатор режима в символе
+func (h symbolHeader) code(mode int) int {
+	if code, ok := h.codeMode[mode]; ok {
+		return code
+	}
+	return mode
+}
+
+//Группа версий для длины счетчика символов
+func groupVersion(version int) int {
+	switch {
+	case version < 9:
+		return 0
+	case version < 26:
+		return 1
+	}
+	return 2
+}
+
+//Сегмент строки в одном режиме кодирования
+type segment struct {
+	mode    int
+	content string
+	//Значение заголовка без данных, например назначение ECI
+	value int
+}
+
+//Состояние разбиения: режим и остаток символов в незаконченной группе
+type splitState struct {
+	mode, rest int
+}
+
+var splitStates = []splitState{
+	{modeNumeric, 0}, {modeNumeric, 1}, {modeNumeric, 2},
+	{modeAlphanum, 0}, {modeAlphanum, 1},
+	{modeByte, 0},
+	{modeKanji, 0},
+}
+
+//Число символов в группе режима
+func unitMode(mode int) int {
+	switch mode {
+	case modeNumeric:
+		return 3
+	case modeAlphanum:
+		return 2
+	}
+	return 1
+}
+
+//Можно ли закодировать символ в режиме
+func canEncode(mode int, r rune, size int, table *charset, sjis *sjisEncoder) bool {
+	switch mode {
+	case modeNumeric:
+		return r >= '0' && r <= '9'
+	case modeAlphanum:
+		return r < 0x80 && strings.IndexByte(alphanumTable, byte(r)) >= 0
+	case modeKanji:
+		_, ok := sjis.code(r)
+		return ok
+	}
+	return lenCharset(r, size, table) > 0
+}
+
+//Число бит, добавляемых символом к группе с остатком rest
+func lenSymbol(mode, rest int, r rune, size int, table *charset) int {
+	switch mode {
+	case modeNumeric:
+		if rest == 0 {
+			return 4
+		}
+		return 3
+	case modeAlphanum:
+		if rest == 0 {
+			return 6
+		}
+		return 5
+	case modeKanji:
+		return 13
+	}
+	return lenCharset(r, size, table) * 8
+}
+
+//Разбиение строки на сегменты минимальной длины для группы версий, nil если строка не кодируется.
+//В режиме FNC1 разделитель групп пишется в буквенно-цифровом режиме как %, а сам % как %%
+func splitSegments(content string, head symbolHeader, table *charset, fnc1 bool) (segments []segment) {
+	const none = -1
+	pos := make([]int, 0, len(content)+1)
+	runes := make([]rune, 0, len(content))
+	for i, r := range content {
+		pos = append(pos, i)
+		runes = append(runes, r)
+	}
+	pos = append(pos, len(content))
+	if len(runes) == 0 {
+		if _, ok := head.lenCount[modeByte]; !ok {
+			return []segment{{mode: modeNumeric}}
+		}
+		return []segment{{mode: modeByte}}
+	}
+
+	sjis := newSJISEncoder()
+	//cost[i][s] минимальная длина первых i символов, последний в состоянии s
+	cost := make([][]int, len(runes)+1)
+	prev := make([][]int, len(runes)+1)
+	for i := range cost {
+		cost[i] = make([]int, len(splitStates))
+		prev[i] = make([]int, len(splitStates))
+		for s := range cost[i] {
+			cost[i][s] = none
+		}
+	}
+	for i, r := range runes {
+		size := pos[i+1] - pos[i]
+		for t, to := range splitStates {
+			lenCount, ok := head.lenCount[to.mode]
+			if !ok {
+				continue
+			}
+			fnc1Alphanum := fnc1 && to.mode == modeAlphanum
+			if !canEncode(to.mode, r, size, table, sjis) && !(fnc1Alphanum && r == groupSeparator) {
+				continue
+			}
+			//Число символов режима, которыми записывается символ строки
+			units := 1
+			if fnc1Alphanum && r == '%' {
+				units = 2
+			}
+			unit := unitMode(to.mode)
+			lenUnits := func(rest int) (length int) {
+				for k := 0; k < units; k++ {
+					length += lenSymbol(to.mode, (rest+k)%unit, r, size, table)
+				}
+				return
+			}
+			//Начало нового сегмента
+			if to.rest == units%unit {
+				start := head.lenMode + lenCount + lenUnits(0)
+				if i == 0 {
+					cost[1][t], prev[1][t] = start, none
+				}
+				for s, from := range splitStates {
+					if i == 0 || cost[i][s] == none || from.mode == to.mode {
+						continue
+					}
+					if c := cost[i][s] + start; cost[i+1][t] == none || c < cost[i+1][t] {
+						cost[i+1][t], prev[i+1][t] = c, s
+					}
+				}
+			}
+			//Продолжение сегмента
+			if i == 0 {
+				continue
+			}
+			for s, from := range splitStates {
+				if from.mode != to.mode || (from.rest+units)%unit != to.rest || cost[i][s] == none {
+					continue
+				}
+				if c := cost[i][s] + lenUnits(from.rest); cost[i+1][t] == none || c < cost[i+1][t] {
+					cost[i+1][t], prev[i+1][t] = c, s
+				}
+			}
+		}
+	}
+
+	best := none
+	for s, c := range cost[len(runes)] {
+		if c != none && (best == none || c < cost[len(runes)][best]) {
+			best = s
+		}
+	}
+	if best == none {
+		return nil
+	}
+	modes := make([]int, len(runes))
+	for i, s := len(runes), best; i > 0; i-- {
+		modes[i-1] = splitStates[s].mode
+		s = prev[i][s]
+	}
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || modes[i] != modes[start] {
+			seg := segment{mode: modes[start], content: content[pos[start]:pos[i]]}
+			switch {
+			case seg.mode == modeByte:
+				seg.content = encodeCharset(seg.content, table)
+			case seg.mode == modeAlphanum && fnc1:
+				seg.content = fnc1Replacer.Replace(seg.content)
+			}
+			segments = append(segments, seg)
+			start = i
+		}
+	}
+	return
+}
+
+//Длина сегмента в битах вместе с режимом и счетчиком символов
+func lenSegment(seg segment, head symbolHeader) int {
+	switch seg.mode {
+	case modeECI:
+		return 4 + lenECI(seg.value)
+	case modeAppend:
+		return lenAppend
+	case modeFNC1First:
+		return 4
+	case modeFNC1Second:
+		return 12
+	}
+	return head.lenMode + head.lenCount[seg.mode] + lenData(seg.content, seg.mode)
+}
+
+//Значение счетчика символов сегмента
+func countSymbol(seg segment) int {
+	if seg.mode == modeKanji {
+		return utf8.RuneCountInString(seg.content)
+	}
+	return len(seg.content)
+}
+
+//Запись цифр в двоичную последовательность по 10 бит на три цифры
+func numericToBit(buf *bitBuffer, content string) {
+	for i := 0; i < len(content); i += 3 {
+		end := i + 3
+		if end > len(content) {
+			end = len(content)
+		}
+		value := 0
+		for _, c := range content[i:end] {
+			value = value*10 + int(c-'0')
+		}
+		buf.put(value, []int{0, 4, 7, 10}[end-i])
+	}
+}
+
+//Запись буквенно-цифровой строки в двоичную последовательность по 11 бит на два символа
+func alphanumToBit(buf *bitBuffer, content string) {
+	for i := 0; i < len(content); i += 2 {
+		value := strings.IndexByte(alphanumTable, content[i])
+		if i+1 < len(content) {
+			buf.put(value*45+strings.IndexByte(alphanumTable, content[i+1]), 11)
+		} else {
+			buf.put(value, 6)
+		}
+	}
+}
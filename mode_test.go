@@ -0,0 +1,55 @@
+package goqr
+
+import "testing"
+
+//Разбиение на сегменты минимальной длины для версий 1–9
+func TestSplitSegments(t *testing.T) {
+	for _, tt := range []struct {
+		content string
+		modes   []int
+		parts   []string
+		length  int
+	}{
+		{"HELLO WORLD", []int{modeAlphanum}, []string{"HELLO WORLD"}, 4 + 9 + 61},
+		//Три цифры перед буквами дороже отдельным сегментом
+		{"123ABC", []int{modeAlphanum}, []string{"123ABC"}, 4 + 9 + 33},
+		{"0123456789ABCDEFabc", []int{modeNumeric, modeAlphanum, modeByte}, []string{"0123456789", "ABCDEF", "abc"}, 48 + 46 + 36},
+		{"a1234567890123b", []int{modeByte, modeNumeric, modeByte}, []string{"a", "1234567890123", "b"}, 20 + 58 + 20},
+		{"日本語abc", []int{modeKanji, modeByte}, []string{"日本語", "abc"}, 51 + 36},
+	} {
+		segments, length := splitCharset(tt.content, qrHeader(0), Options{})
+		if length != tt.length || len(segments) != len(tt.modes) {
+			t.Errorf("%q: %d segments length %d, want %d %d", tt.content, len(segments), length, len(tt.modes), tt.length)
+			continue
+		}
+		for i, seg := range segments {
+			if seg.mode != tt.modes[i] || seg.content != tt.parts[i] {
+				t.Errorf("%q: segment %d mode %d %q, want %d %q", tt.content, i, seg.mode, seg.content, tt.modes[i], tt.parts[i])
+			}
+		}
+	}
+}
+
+//Длина данных в битах по режимам
+func TestLenData(t *testing.T) {
+	for _, tt := range []struct {
+		content string
+		mode    int
+		length  int
+	}{
+		{"01234567", modeNumeric, 27},
+		{"0123", modeNumeric, 14},
+		{"AC-42", modeAlphanum, 28},
+		{"日本", modeKanji, 26},
+		{"abc", modeByte, 24},
+	} {
+		if got := lenData(tt.content, tt.mode); got != tt.length {
+			t.Errorf("lenData(%q, %d) = %d, want %d", tt.content, tt.mode, got, tt.length)
+		}
+		buf := newBitBuffer(tt.length)
+		utfToBit(buf, tt.content, tt.mode)
+		if buf.len() != tt.length {
+			t.Errorf("%q: written %d bits, want %d", tt.content, buf.len(), tt.length)
+		}
+	}
+}
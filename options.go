@@ -0,0 +1,481 @@
+package goqr
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//Option настройка генерации для Generate и GenerateFile
+type Option func(*config) error
+
+//Настройки генерации
+type config struct {
+	opt    Options
+	style  Style
+	format Format
+	symbol symbolKind
+	//Высота rmqr, 0 выбирает символ наименьшей площади
+	height int
+}
+
+//Вид символа
+type symbolKind byte
+
+const (
+	symbolQR symbolKind = iota
+	symbolMicro
+	symbolRMQR
+)
+
+//Настройки по умолчанию: уровень по картинке, тихая зона 4 модуля, модуль 1 пиксель, черный на белом,
+//картинка на 0.2 области данных, качество jpeg 75
+func defaultConfig() config {
+	return config{style: Style{
+		QuietZone:  4,
+		ModuleSize: 1,
+		Dark:       color.Black,
+		Light:      color.White,
+		LogoRatio:  0.2,
+		Quality:    jpeg.DefaultQuality,
+	}}
+}
+
+//Применение настроек по порядку до первой ошибки
+func newConfig(opts []Option) (config, error) {
+	c := defaultConfig()
+	for _, o := range opts {
+		if err := o(&c); err != nil {
+			return c, err
+		}
+	}
+	return c, c.check()
+}
+
+//Проверка сочетания настроек после применения всех Option
+func (c config) check() error {
+	if min, max := c.opt.versions(); min > max {
+		return fmt.Errorf("min version %d greater than max version %d", min+1, max+1)
+	}
+	if c.symbol == symbolQR {
+		return nil
+	}
+	name := "micro qr"
+	if c.symbol == symbolRMQR {
+		name = "rmqr"
+	}
+	if c.style.Logo != nil {
+		return fmt.Errorf("logo not supported in %s", name)
+	}
+	if c.opt.Charset != CharsetDefault || c.opt.Append != AppendNone || c.opt.FNC1 != FNC1None || c.opt.MinVersion != 0 || c.opt.MaxVersion != 0 {
+		return fmt.Errorf("option not supported in %s", name)
+	}
+	if c.symbol == symbolMicro {
+		if c.opt.Level > LevelQ {
+			return fmt.Errorf("level %d wrong, want LevelAuto to LevelQ in micro qr", c.opt.Level)
+		}
+		if c.opt.Mask > Mask3 {
+			return fmt.Errorf("mask %d wrong, want MaskAuto to Mask3 in micro qr", c.opt.Mask)
+		}
+		return nil
+	}
+	if _, ok := rmqrLevelTables[c.opt.Level]; !ok && c.opt.Level != LevelAuto {
+		return fmt.Errorf("level %d wrong, want LevelAuto, LevelM or LevelH in rmqr", c.opt.Level)
+	}
+	if c.opt.Mask != MaskAuto {
+		return fmt.Errorf("mask %d wrong, want MaskAuto in rmqr", c.opt.Mask)
+	}
+	return nil
+}
+
+//Generate генерирует qr и пишет его в w. AppendFiles недоступен, используйте AppendSheet
+func Generate(w io.Writer, content string, opts ...Option) error {
+	c, err := newConfig(opts)
+	if err != nil {
+		return err
+	}
+	return c.write(w, content)
+}
+
+//GenerateFile генерирует qr в файл qrPath, при AppendFiles в файлы с номерами qr-1.png, qr-2.png и так далее.
+//Без WithFormat формат выбирается по расширению qrPath, незарегистрированное расширение возвращает ошибку,
+//файл без расширения пишется в png или gif для гифки
+func GenerateFile(content, qrPath string, opts ...Option) error {
+	if qrPath == "" {
+		return errors.New("qrPath is nil")
+	}
+	c, err := newConfig(opts)
+	if err != nil {
+		return err
+	}
+	return c.saveFile(content, qrPath)
+}
+
+//Запись qr в w
+func (c config) write(w io.Writer, content string) error {
+	codes, err := c.encode(content)
+	if err != nil {
+		return err
+	}
+	return c.render(w, codes)
+}
+
+//Запись qr в файл
+func (c config) saveFile(content, qrPath string) error {
+	codes, err := c.encode(content)
+	if err != nil {
+		return err
+	}
+	return c.save(qrPath, codes)
+}
+
+//Кодирование строки в символ, при объединении в несколько qr.
+//С картинкой LevelAuto в qr означает LevelH, иначе LevelM
+func (c config) encode(content string) ([]*Code, error) {
+	if c.symbol != symbolQR {
+		code, err := c.encodeSymbol(content)
+		if err != nil {
+			return nil, err
+		}
+		return []*Code{code}, nil
+	}
+	if c.opt.Level == LevelAuto {
+		c.opt.Level = LevelM
+		if c.style.Logo != nil {
+			c.opt.Level = LevelH
+		}
+	}
+	var codes []*Code
+	if c.opt.Append != AppendNone {
+		var err error
+		if codes, err = EncodeAppend(content, c.opt); err != nil {
+			return nil, err
+		}
+	} else {
+		code, err := Encode(content, c.opt)
+		if err != nil {
+			return nil, err
+		}
+		codes = []*Code{code}
+	}
+	if c.style.Logo != nil && logoModules(codes[0].version, c.style.LogoRatio) < 1 {
+		return nil, fmt.Errorf("logo ratio %g too small for version %d", c.style.LogoRatio, codes[0].Version())
+	}
+	return codes, nil
+}
+
+//Кодирование строки в micro qr или rmqr
+func (c config) encodeSymbol(content string) (*Code, error) {
+	if c.symbol == symbolRMQR {
+		return EncodeRMQR(content, c.height, c.opt)
+	}
+	return EncodeMicro(content, c.opt)
+}
+
+//Вывод символов в файл, формат по расширению если не задан. При AppendFiles в файлы с номерами
+func (c config) save(qrPath string, codes []*Code) error {
+	if ext := filepath.Ext(qrPath); c.format == FormatAuto && ext != "" {
+		format, ok := FormatByExt(qrPath)
+		if !ok {
+			return fmt.Errorf("format extension %q not registered", ext)
+		}
+		c.format = format
+	}
+	if len(codes) == 1 || c.opt.Append != AppendFiles {
+		return c.saveOne(qrPath, codes)
+	}
+	ext := filepath.Ext(qrPath)
+	for i := range codes {
+		if err := c.saveOne(fmt.Sprintf("%s-%d%s", strings.TrimSuffix(qrPath, ext), i+1, ext), codes[i:i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Вывод символов в один файл
+func (c config) saveOne(qrPath string, codes []*Code) error {
+	file, err := os.Create(qrPath)
+	if err != nil {
+		return err
+	}
+	if err := c.render(file, codes); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+//WithLevel уровень коррекции ошибок
+func WithLevel(level Level) Option {
+	return func(c *config) error {
+		if level > LevelH {
+			return fmt.Errorf("level %d wrong, want LevelAuto to LevelH", level)
+		}
+		c.opt.Level = level
+		return nil
+	}
+}
+
+//WithVersion фиксированная версия 1–40
+func WithVersion(version int) Option {
+	return func(c *config) error {
+		if version < 1 || version > 40 {
+			return fmt.Errorf("version %d wrong, want 1 to 40", version)
+		}
+		c.opt.MinVersion, c.opt.MaxVersion = version, version
+		return nil
+	}
+}
+
+//WithMinVersion наименьшая версия 1–40
+func WithMinVersion(version int) Option {
+	return func(c *config) error {
+		if version < 1 || version > 40 {
+			return fmt.Errorf("min version %d wrong, want 1 to 40", version)
+		}
+		c.opt.MinVersion = version
+		return nil
+	}
+}
+
+//WithMaxVersion наибольшая версия 1–40
+func WithMaxVersion(version int) Option {
+	return func(c *config) error {
+		if version < 1 || version > 40 {
+			return fmt.Errorf("max version %d wrong, want 1 to 40", version)
+		}
+		c.opt.MaxVersion = version
+		return nil
+	}
+}
+
+//WithMask шаблон маски
+func WithMask(mask Mask) Option {
+	return func(c *config) error {
+		if mask > Mask7 {
+			return fmt.Errorf("mask %d wrong, want MaskAuto to Mask7", mask)
+		}
+		c.opt.Mask = mask
+		return nil
+	}
+}
+
+//WithCharset кодировка байтового режима
+func WithCharset(charset Charset) Option {
+	return func(c *config) error {
+		if _, ok := charsets[charset]; !ok && charset != CharsetAuto {
+			return fmt.Errorf("charset %d wrong", charset)
+		}
+		c.opt.Charset = charset
+		return nil
+	}
+}
+
+//WithAppend вывод структурированного объединения для данных больше одного qr
+func WithAppend(append Append) Option {
+	return func(c *config) error {
+		if append > AppendSheet {
+			return fmt.Errorf("append %d wrong, want AppendNone to AppendSheet", append)
+		}
+		c.opt.Append = append
+		return nil
+	}
+}
+
+//WithFNC1 режим FNC1, идентификатор применения app нужен только для FNC1Second
+func WithFNC1(fnc1 FNC1, app string) Option {
+	return func(c *config) error {
+		if fnc1 > FNC1Second {
+			return fmt.Errorf("fnc1 %d wrong, want FNC1None to FNC1Second", fnc1)
+		}
+		if _, err := appIndicator(app); fnc1 == FNC1Second && err != nil {
+			return err
+		}
+		c.opt.FNC1, c.opt.AppIndicator = fnc1, app
+		return nil
+	}
+}
+
+//WithMicro micro qr M1–M4 размером от 11x11 до 17x17 вместо qr.
+//LevelAuto выбирает наименьший символ с наибольшей коррекцией, LevelH недоступен.
+//Маски Mask0–Mask3 соответствуют шаблонам micro qr 00–11, картинка недоступна
+func WithMicro() Option {
+	return func(c *config) error {
+		c.symbol = symbolMicro
+		return nil
+	}
+}
+
+//WithRMQR прямоугольный micro qr от R7x43 до R17x139 вместо qr.
+//height ограничивает высоту символа 7–17, 0 выбирает символ наименьшей площади.
+//Доступны уровни LevelM и LevelH, LevelAuto означает LevelM, картинка недоступна
+func WithRMQR(height int) Option {
+	return func(c *config) error {
+		if height != 0 && (height < rmqrHeight[0] || height > rmqrHeight[len(rmqrHeight)-1] || height%2 == 0) {
+			return fmt.Errorf("rmqr height %d wrong, want 0 or odd 7 to 17", height)
+		}
+		c.symbol, c.height = symbolRMQR, height
+		return nil
+	}
+}
+
+//WithQuietZone ширина тихой зоны в модулях, по умолчанию 4
+func WithQuietZone(modules int) Option {
+	return func(c *config) error {
+		if modules < 0 {
+			return fmt.Errorf("quiet zone %d wrong, want 0 or more", modules)
+		}
+		c.style.QuietZone = modules
+		return nil
+	}
+}
+
+//WithModuleSize размер модуля в пикселях, по умолчанию 1. С картинкой модуль может быть больше
+func WithModuleSize(px int) Option {
+	return func(c *config) error {
+		if px < 1 {
+			return fmt.Errorf("module size %d wrong, want 1 or more", px)
+		}
+		c.style.ModuleSize = px
+		return nil
+	}
+}
+
+//WithPhysicalSize ширина символа с тихой зоной для векторных форматов, например 30 UnitMM
+func WithPhysicalSize(size float64, unit Unit) Option {
+	return func(c *config) error {
+		if int(unit) >= len(unitPoints) {
+			return fmt.Errorf("unit %d wrong, want UnitPoint, UnitMM or UnitInch", unit)
+		}
+		if size <= 0 {
+			return fmt.Errorf("physical size %g wrong, want greater than 0", size)
+		}
+		c.style.PhysicalSize = size * unitPoints[unit]
+		return nil
+	}
+}
+
+//WithInvert инверсия текстовых форматов для темных терминалов
+func WithInvert() Option {
+	return func(c *config) error {
+		c.style.Invert = true
+		return nil
+	}
+}
+
+//WithColors цвета темных и светлых модулей
+func WithColors(dark, light color.Color) Option {
+	return func(c *config) error {
+		if dark == nil || light == nil {
+			return errors.New("colors wrong, want non nil dark and light")
+		}
+		c.style.Dark, c.style.Light = dark, light
+		return nil
+	}
+}
+
+//WithLogo картинка в центре qr: image.Image, *gif.GIF или io.Reader с jpeg, png или gif
+func WithLogo(logo interface{}) Option {
+	return func(c *config) error {
+		switch l := logo.(type) {
+		case *gif.GIF:
+			if len(l.Image) == 0 {
+				return errors.New("logo gif has no frames")
+			}
+			c.style.Logo = l
+		case image.Image:
+			c.style.Logo = l
+		case io.Reader:
+			gachi, err := decodeLogo(l)
+			if err != nil {
+				return err
+			}
+			c.style.Logo = gachi
+		default:
+			return fmt.Errorf("logo type %T wrong, want image.Image, *gif.GIF or io.Reader", logo)
+		}
+		return nil
+	}
+}
+
+//WithLogoFile картинка в центре qr из файла
+func WithLogoFile(path string) Option {
+	return func(c *config) error {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return WithLogo(file)(c)
+	}
+}
+
+//WithLogoFS картинка в центре qr из fsys, например embed.FS
+func WithLogoFS(fsys fs.FS, name string) Option {
+	return func(c *config) error {
+		file, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return WithLogo(file)(c)
+	}
+}
+
+//Наибольшая доля области данных под картинку. LevelH восстанавливает 30% кодовых слов,
+//картинка задевает кодовые слова и по краям, поэтому берется запас
+const maxLogoRatio = 0.25
+
+//WithLogoRatio доля области данных под картинку больше 0 и не больше 0.25, по умолчанию 0.2
+func WithLogoRatio(ratio float64) Option {
+	return func(c *config) error {
+		if ratio <= 0 || ratio > maxLogoRatio {
+			return fmt.Errorf("logo ratio %g wrong, want between 0 and %g", ratio, maxLogoRatio)
+		}
+		c.style.LogoRatio = ratio
+		return nil
+	}
+}
+
+//WithFormat формат вывода из зарегистрированных
+func WithFormat(format Format) Option {
+	return func(c *config) error {
+		if _, ok := LookupRenderer(format); !ok && format != FormatAuto {
+			return fmt.Errorf("format %q not registered", format)
+		}
+		c.format = format
+		return nil
+	}
+}
+
+//WithFormatExt формат по расширению имени файла, например .png, .jpg, .jpeg или .gif
+func WithFormatExt(name string) Option {
+	return func(c *config) error {
+		format, ok := FormatByExt(name)
+		if !ok {
+			return fmt.Errorf("format extension %q not registered", filepath.Ext(name))
+		}
+		c.format = format
+		return nil
+	}
+}
+
+//WithJPEGQuality качество jpeg 1–100, по умолчанию 75
+func WithJPEGQuality(quality int) Option {
+	return func(c *config) error {
+		if quality < 1 || quality > 100 {
+			return fmt.Errorf("jpeg quality %d wrong, want 1 to 100", quality)
+		}
+		c.style.Quality = quality
+		return nil
+	}
+}
@@ -0,0 +1,135 @@
+package goqr
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//Неверные значения и несовместимые сочетания настроек
+func TestNewConfigWrong(t *testing.T) {
+	logo := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for name, opts := range map[string][]Option{
+		"level":             {WithLevel(LevelH + 1)},
+		"version 0":         {WithVersion(0)},
+		"version 41":        {WithVersion(41)},
+		"min version":       {WithMinVersion(-1)},
+		"max version":       {WithMaxVersion(41)},
+		"min over max":      {WithMinVersion(10), WithMaxVersion(5)},
+		"mask":              {WithMask(Mask7 + 1)},
+		"charset":           {WithCharset(Charset(255))},
+		"append":            {WithAppend(AppendSheet + 1)},
+		"fnc1":              {WithFNC1(FNC1Second+1, "")},
+		"app indicator":     {WithFNC1(FNC1Second, "123")},
+		"rmqr height":       {WithRMQR(8)},
+		"quiet zone":        {WithQuietZone(-1)},
+		"module size":       {WithModuleSize(0)},
+		"physical size":     {WithPhysicalSize(0, UnitMM)},
+		"unit":              {WithPhysicalSize(30, Unit(255))},
+		"colors":            {WithColors(nil, color.White)},
+		"logo ratio":        {WithLogoRatio(0.5)},
+		"logo type":         {WithLogo("logo.png")},
+		"micro logo":        {WithLogo(logo), WithMicro()},
+		"rmqr logo":         {WithRMQR(0), WithLogo(logo)},
+		"micro level":       {WithMicro(), WithLevel(LevelH)},
+		"micro mask":        {WithMicro(), WithMask(Mask4)},
+		"micro append":      {WithMicro(), WithAppend(AppendSheet)},
+		"micro version":     {WithMicro(), WithVersion(1)},
+		"rmqr level":        {WithRMQR(7), WithLevel(LevelL)},
+		"rmqr mask":         {WithRMQR(7), WithMask(Mask1)},
+		"rmqr charset":      {WithRMQR(7), WithCharset(CharsetWindows1251)},
+		"rmqr fnc1":         {WithRMQR(7), WithFNC1(FNC1First, "")},
+		"first error wins":  {WithQuietZone(-1), WithQuietZone(2)},
+		"format not exists": {WithFormat(Format("nope"))},
+	} {
+		if _, err := newConfig(opts); err == nil {
+			t.Errorf("%s: want error", name)
+		}
+	}
+}
+
+//Допустимые сочетания настроек
+func TestNewConfig(t *testing.T) {
+	logo := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for name, opts := range map[string][]Option{
+		"default":     nil,
+		"qr":          {WithLevel(LevelQ), WithVersion(5), WithMask(Mask7), WithCharset(CharsetAuto), WithAppend(AppendFiles), WithFNC1(FNC1Second, "a")},
+		"qr logo":     {WithLogo(logo), WithLogoRatio(maxLogoRatio)},
+		"micro":       {WithMicro(), WithLevel(LevelQ), WithMask(Mask3)},
+		"rmqr":        {WithRMQR(17), WithLevel(LevelH)},
+		"rmqr height": {WithRMQR(0)},
+	} {
+		if _, err := newConfig(opts); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+//Encode, EncodeMicro и EncodeRMQR проверяют Options теми же функциями With*
+func TestEncodeOptionsWrong(t *testing.T) {
+	for name, opt := range map[string]Options{
+		"level":         {Level: LevelH + 1},
+		"mask":          {Mask: Mask7 + 1},
+		"charset":       {Charset: Charset(255)},
+		"fnc1":          {FNC1: FNC1Second + 1},
+		"app indicator": {FNC1: FNC1Second, AppIndicator: "ab"},
+		"min version":   {MinVersion: -1},
+		"max version":   {MaxVersion: 41},
+		"min over max":  {MinVersion: 10, MaxVersion: 5},
+	} {
+		if _, err := Encode("0123456789", opt); err == nil {
+			t.Errorf("Encode %s: want error", name)
+		}
+	}
+	if _, err := EncodeMicro("0123", Options{Level: LevelH}); err == nil {
+		t.Error("EncodeMicro LevelH: want error")
+	}
+	if _, err := EncodeMicro("0123", Options{MaxVersion: 1}); err == nil {
+		t.Error("EncodeMicro max version: want error")
+	}
+	if _, err := EncodeRMQR("0123", 8, Options{}); err == nil {
+		t.Error("EncodeRMQR height 8: want error")
+	}
+	if _, err := EncodeRMQR("0123", 7, Options{Level: LevelQ}); err == nil {
+		t.Error("EncodeRMQR LevelQ: want error")
+	}
+}
+
+//Формат файла по расширению, незарегистрированное расширение без WithFormat возвращает ошибку
+func TestGenerateFileExt(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		name   string
+		opts   []Option
+		prefix string
+	}{
+		{"qr.png", nil, "\x89PNG"},
+		{"qr.SVG", nil, "<?xml"},
+		{"qr", nil, "\x89PNG"},
+		{"qr.xyz", []Option{WithFormat(FormatSVG)}, "<?xml"},
+		{"qr.unknown", nil, ""},
+	} {
+		path := filepath.Join(dir, tt.name)
+		err := GenerateFile("0123456789", path, tt.opts...)
+		if tt.prefix == "" {
+			if err == nil {
+				t.Errorf("%s: want error", tt.name)
+			}
+			if _, err := os.Stat(path); err == nil {
+				t.Errorf("%s: file created", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		data, _ := os.ReadFile(path)
+		if !bytes.HasPrefix(data, []byte(tt.prefix)) {
+			t.Errorf("%s: starts with %.8q, want %q", tt.name, data, tt.prefix)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package goqr
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"testing"
+)
+
+//Картинка со смещенными границами, например SubImage, рисуется целиком
+func TestPaintImageSubImage(t *testing.T) {
+	code, err := Encode("logo", Options{Level: LevelH, MinVersion: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	red := color.NRGBA{255, 0, 0, 255}
+	full := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	draw.Draw(full, full.Rect, image.NewUniform(color.NRGBA{0, 0, 255, 255}), image.Point{}, draw.Src)
+	draw.Draw(full, image.Rect(16, 16, 48, 48), image.NewUniform(red), image.Point{}, draw.Src)
+	style := defaultConfig().style
+	style.Logo = full.SubImage(image.Rect(16, 16, 48, 48))
+
+	img := paintCode(code, style).(image.Image)
+	b := img.Bounds()
+	for _, p := range []image.Point{{b.Dx() / 2, b.Dy() / 2}, {b.Dx()/2 - 12, b.Dy()/2 - 12}, {b.Dx()/2 + 12, b.Dy()/2 + 12}} {
+		if c := color.NRGBAModel.Convert(img.At(p.X, p.Y)); c != red {
+			t.Errorf("pixel %v = %v, want %v", p, c, red)
+		}
+	}
+}
+
+//Png без картинки пишется с палитрой из двух цветов
+func TestGeneratePaletted(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Generate(&buf, "HELLO WORLD", WithColors(color.RGBA{0, 0, 0x80, 0xff}, color.White)); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := img.(*image.Paletted)
+	if !ok {
+		t.Fatalf("image %T, want *image.Paletted", img)
+	}
+	want := color.Palette{color.NRGBA{0xff, 0xff, 0xff, 0xff}, color.NRGBA{0, 0, 0x80, 0xff}}
+	if len(p.Palette) != len(want) {
+		t.Fatalf("palette %d colors, want %d", len(p.Palette), len(want))
+	}
+	for i, c := range want {
+		if color.NRGBAModel.Convert(p.Palette[i]) != c {
+			t.Errorf("palette %d: %v, want %v", i, p.Palette[i], c)
+		}
+	}
+}
+
+//Картинка до 254 цветов остается в палитре вместе со светлым и темным, больше 256 цветов дают NRGBA
+func TestGenerateLogoPaletted(t *testing.T) {
+	for _, tt := range []struct {
+		colors   int
+		paletted bool
+	}{
+		{1, true},
+		{254, true},
+		{300, false},
+	} {
+		logo := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+		for i := 0; i < 20*20; i++ {
+			k := i % tt.colors
+			logo.SetNRGBA(i%20, i/20, color.NRGBA{uint8(k), uint8(k >> 8), 0x80, 0xff})
+		}
+		var buf bytes.Buffer
+		if err := Generate(&buf, "HELLO WORLD", WithLogo(logo), WithMinVersion(10), WithModuleSize(2)); err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := img.(*image.Paletted); ok != tt.paletted {
+			t.Errorf("%d colors: image %T, want paletted %v", tt.colors, img, tt.paletted)
+		}
+		//Все цвета картинки сохраняются без изменений
+		colors := map[color.NRGBA]bool{}
+		b := img.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				if c.B == 0x80 {
+					colors[c] = true
+				}
+			}
+		}
+		if len(colors) != tt.colors {
+			t.Errorf("%d colors: %d logo colors in png", tt.colors, len(colors))
+		}
+	}
+}
+
+//Доля картинки ограничена восстановлением LevelH, слишком малая доля для версии возвращает ошибку
+func TestLogoRatio(t *testing.T) {
+	for _, ratio := range []float64{0, -0.1, 0.26, 0.9} {
+		if _, err := newConfig([]Option{WithLogoRatio(ratio)}); err == nil {
+			t.Errorf("ratio %g: want error", ratio)
+		}
+	}
+	logo := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	if err := Generate(io.Discard, "logo", WithLogo(logo), WithLogoRatio(0.005)); err == nil {
+		t.Error("ratio 0.005 in version 1: want error")
+	}
+	if err := Generate(io.Discard, "logo", WithLogo(logo), WithLogoRatio(maxLogoRatio)); err != nil {
+		t.Error(err)
+	}
+	if size := logoModules(0, 0.005); size >= 1 {
+		t.Errorf("logoModules(0, 0.005) = %d, want less than 1", size)
+	}
+}
+
+//Рисование qr версий 1, 10 и 40 без картинки и с картинкой
+func BenchmarkPaintImage(b *testing.B) {
+	logo := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for _, version := range []int{1, 10, 40} {
+		code, err := Encode("benchmark", Options{MinVersion: version})
+		if err != nil {
+			b.Fatal(err)
+		}
+		style := defaultConfig().style
+		style.ModuleSize = 4
+		b.Run("v"+strconv.Itoa(version), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				paintCode(code, style)
+			}
+		})
+		style.Logo = logo
+		b.Run("v"+strconv.Itoa(version)+"-logo", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				paintCode(code, style)
+			}
+		})
+	}
+}
+
+//Кодирование, рисование и сжатие png qr версий 1, 10 и 40 без картинки и с картинкой
+func BenchmarkGenerate(b *testing.B) {
+	logo := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for _, version := range []int{1, 10, 40} {
+		opts := []Option{WithVersion(version), WithLevel(LevelM), WithModuleSize(4), WithFormat(FormatPNG)}
+		b.Run("v"+strconv.Itoa(version), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := Generate(io.Discard, "benchmark", opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		opts = append(opts, WithLogo(logo))
+		b.Run("v"+strconv.Itoa(version)+"-logo", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := Generate(io.Discard, "benchmark", opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
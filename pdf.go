@@ -0,0 +1,157 @@
+package goqr
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+)
+
+//FormatPDF pdf из одной страницы размером с символ, модули рисуются прямоугольниками в DeviceCMYK
+const FormatPDF Format = "pdf"
+
+func init() {
+	RegisterRenderer(FormatPDF, RendererFunc(renderPDF), ".pdf")
+}
+
+//Вывод символа в pdf: страница размером с символ с тихой зоной, картинка отдельным XObject
+func renderPDF(w io.Writer, code *Code, style Style) error {
+	module := style.modulePoints(code)
+	width, height := code.Width()+2*style.QuietZone, code.Height()+2*style.QuietZone
+
+	//Содержимое страницы в модулях с началом в левом верхнем углу
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "%s 0 0 %s 0 %s cm\n", pdfNumber(module), pdfNumber(-module), pdfNumber(module*float64(height)))
+	fmt.Fprintf(&content, "%s\n0 0 %d %d re f\n", pdfFill(style.Light), width, height)
+	fmt.Fprintf(&content, "%s\n", pdfFill(style.Dark))
+	for _, r := range darkRects(code, style) {
+		fmt.Fprintf(&content, "%d %d %d %d re\n", r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+	}
+	fmt.Fprintf(&content, "f\n")
+
+	var objects [][]byte
+	resources := "<< >>"
+	if area := logoArea(code, style); !area.Empty() {
+		fmt.Fprintf(&content, "q %d 0 0 %d %d %d cm /Logo Do Q\n", area.Dx(), -area.Dy(), area.Min.X, area.Max.Y)
+		img, mask, err := pdfImage(style.Logo)
+		if err != nil {
+			return err
+		}
+		//Картинка пятым объектом, маска прозрачности шестым
+		resources = "<< /XObject << /Logo 5 0 R >> >>"
+		objects = append(objects, img)
+		if mask != nil {
+			objects = append(objects, mask)
+		}
+	}
+
+	stream, err := pdfStream("", content.Bytes())
+	if err != nil {
+		return err
+	}
+	objects = append([][]byte{
+		[]byte("<< /Type /Catalog /Pages 2 0 R >>"),
+		[]byte("<< /Type /Pages /Kids [3 0 R] /Count 1 >>"),
+		[]byte(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents 4 0 R >>",
+			pdfNumber(module*float64(width)), pdfNumber(module*float64(height)), resources)),
+		stream,
+	}, objects...)
+
+	//Объекты с таблицей смещений
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+//Картинка в DeviceRGB и маска прозрачности в DeviceGray следующим объектом, nil если картинка непрозрачная.
+//Из гифки берется первый кадр
+func pdfImage(logo interface{}) (img, mask []byte, err error) {
+	var src image.Image
+	switch l := logo.(type) {
+	case *gif.GIF:
+		src = l.Image[0]
+	case image.Image:
+		src = l
+	}
+	b := src.Bounds()
+	rgb := make([]byte, 0, b.Dx()*b.Dy()*3)
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 0xff
+		}
+	}
+	head := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8", b.Dx(), b.Dy())
+	if opaque {
+		img, err = pdfStream(head+" /ColorSpace /DeviceRGB", rgb)
+		return img, nil, err
+	}
+	if img, err = pdfStream(head+" /ColorSpace /DeviceRGB /SMask 6 0 R", rgb); err != nil {
+		return nil, nil, err
+	}
+	mask, err = pdfStream(head+" /ColorSpace /DeviceGray", alpha)
+	return img, mask, err
+}
+
+//Поток со сжатием FlateDecode и дополнительными ключами словаря
+func pdfStream(dict string, data []byte) ([]byte, error) {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<< %s /Length %d /Filter /FlateDecode >>\nstream\n", dict, z.Len())
+	buf.Write(z.Bytes())
+	buf.WriteString("\nendstream")
+	return buf.Bytes(), nil
+}
+
+//Цвет заливки в DeviceCMYK
+func pdfFill(c color.Color) string {
+	return cmykNumbers(c) + " k"
+}
+
+//Компоненты цвета в DeviceCMYK от 0 до 1 для pdf и eps
+func cmykNumbers(c color.Color) string {
+	cmyk := color.CMYKModel.Convert(c).(color.CMYK)
+	return fmt.Sprintf("%s %s %s %s", pdfNumber(float64(cmyk.C)/0xff), pdfNumber(float64(cmyk.M)/0xff),
+		pdfNumber(float64(cmyk.Y)/0xff), pdfNumber(float64(cmyk.K)/0xff))
+}
+
+//Число pdf и eps без экспоненты и лишних нулей
+func pdfNumber(v float64) string {
+	s := fmt.Sprintf("%.4f", v)
+	for s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	if s[len(s)-1] == '.' {
+		s = s[:len(s)-1]
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
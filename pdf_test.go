@@ -0,0 +1,134 @@
+package goqr
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//Смещения таблицы xref и startxref указывают на объекты и на саму таблицу
+func checkPDFXref(t *testing.T, pdf []byte) int {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("pdf without startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n0 ")) {
+		t.Fatalf("startxref %d does not point to xref", xref)
+	}
+	var count int
+	fmt.Sscanf(string(pdf[xref+len("xref\n0 "):]), "%d", &count)
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(pdf[xref:], -1)
+	if len(entries) != count-1 {
+		t.Fatalf("xref entries %d, want %d", len(entries), count-1)
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if obj := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[off:], []byte(obj)) {
+			t.Errorf("xref object %d offset %d points to %.12q", i+1, off, pdf[off:])
+		}
+	}
+	return count - 1
+}
+
+//Страница размером 30 мм, заливки в DeviceCMYK и корректная таблица смещений
+func TestRenderPDF(t *testing.T) {
+	var buf bytes.Buffer
+	err := Generate(&buf, "HELLO WORLD", WithFormat(FormatPDF), WithPhysicalSize(30, UnitMM),
+		WithColors(color.RGBA{255, 0, 0, 255}, color.White))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdf := buf.Bytes()
+	if objects := checkPDFXref(t, pdf); objects != 4 {
+		t.Errorf("objects %d, want 4", objects)
+	}
+	side := pdfNumber(30 * 72 / 25.4)
+	if box := "/MediaBox [0 0 " + side + " " + side + "]"; !bytes.Contains(pdf, []byte(box)) {
+		t.Errorf("pdf without %s", box)
+	}
+	if side != "85.0394" {
+		t.Errorf("side %s, want 85.0394", side)
+	}
+	if bytes.Contains(pdf, []byte("/XObject")) {
+		t.Error("xobject without logo")
+	}
+
+	//Содержимое страницы сжато, заливки проверяются по потоку
+	content := pdfContent(t, Style{QuietZone: 4, ModuleSize: 1, Dark: color.RGBA{255, 0, 0, 255}, Light: color.Gray{0xff}})
+	for _, fill := range []string{"0 0 0 0 k\n0 0 45 45 re f", "0 1 1 0 k\n"} {
+		if !strings.Contains(content, fill) {
+			t.Errorf("content without %q", fill)
+		}
+	}
+	if strings.Contains(content, " rg") {
+		t.Error("rgb fill in content")
+	}
+}
+
+//Картинка подключается XObject /Logo, прозрачная картинка с маской SMask
+func TestRenderPDFLogo(t *testing.T) {
+	logo := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	logo.Set(1, 1, color.NRGBA{0, 0, 255, 255})
+	var buf bytes.Buffer
+	if err := Generate(&buf, "HELLO WORLD 0123456789", WithFormat(FormatPDF), WithLogo(logo), WithMinVersion(5)); err != nil {
+		t.Fatal(err)
+	}
+	pdf := buf.Bytes()
+	if objects := checkPDFXref(t, pdf); objects != 6 {
+		t.Errorf("objects %d, want 6", objects)
+	}
+	for _, s := range []string{
+		"/Resources << /XObject << /Logo 5 0 R >> >>",
+		"5 0 obj\n<< /Type /XObject /Subtype /Image /Width 4 /Height 4 /BitsPerComponent 8 /ColorSpace /DeviceRGB /SMask 6 0 R",
+		"6 0 obj\n<< /Type /XObject /Subtype /Image /Width 4 /Height 4 /BitsPerComponent 8 /ColorSpace /DeviceGray",
+	} {
+		if !bytes.Contains(pdf, []byte(s)) {
+			t.Errorf("pdf without %q", s)
+		}
+	}
+	if !strings.Contains(pdfContent(t, Style{QuietZone: 4, ModuleSize: 1, Dark: color.Black, Light: color.White, Logo: logo, LogoRatio: 0.2}), "/Logo Do") {
+		t.Error("content without /Logo Do")
+	}
+}
+
+//Распакованный поток содержимого страницы qr версии 5
+func pdfContent(t *testing.T, style Style) string {
+	t.Helper()
+	code, err := Encode("HELLO WORLD 0123456789", Options{Level: LevelH, MinVersion: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := renderPDF(&buf, code, style); err != nil {
+		t.Fatal(err)
+	}
+	return string(pdfInflate(t, buf.Bytes(), 4))
+}
+
+//Распакованный поток объекта с номером obj
+func pdfInflate(t *testing.T, pdf []byte, obj int) []byte {
+	t.Helper()
+	m := regexp.MustCompile(fmt.Sprintf(`(?s)\n%d 0 obj\n<<.*? /Length (\d+) /Filter /FlateDecode >>\nstream\n`, obj)).FindSubmatchIndex(pdf)
+	if m == nil {
+		t.Fatalf("pdf without stream object %d", obj)
+	}
+	length, _ := strconv.Atoi(string(pdf[m[2]:m[3]]))
+	zr, err := zlib.NewReader(bytes.NewReader(pdf[m[1] : m[1]+length]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
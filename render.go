@@ -0,0 +1,354 @@
+package goqr
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//Format формат вывода, имя зарегистрированного Renderer
+type Format string
+
+const (
+	//FormatAuto gif с гифкой, иначе png, в файл по расширению
+	FormatAuto Format = ""
+	//FormatPNG png, из гифки берется первый кадр
+	FormatPNG Format = "png"
+	//FormatGIF gif
+	FormatGIF Format = "gif"
+	//FormatJPEG jpeg с качеством Style.Quality, из гифки берется первый кадр
+	FormatJPEG Format = "jpeg"
+)
+
+//Style оформление символа для Renderer
+type Style struct {
+	//Тихая зона в модулях
+	QuietZone int
+	//Размер модуля в пикселях
+	ModuleSize int
+	//Цвета темных и светлых модулей
+	Dark, Light color.Color
+	//Картинка в центре qr: nil, image.Image или *gif.GIF
+	Logo interface{}
+	//Доля области данных под картинку больше 0 и не больше 0.25
+	LogoRatio float64
+	//Качество сжатия с потерями 1–100
+	Quality int
+	//Ширина символа с тихой зоной в пунктах для векторных форматов, 0 означает ModuleSize пунктов на модуль
+	PhysicalSize float64
+	//Инверсия текстовых форматов для темных терминалов: знаками рисуются светлые модули
+	Invert bool
+}
+
+//Unit единица физического размера
+type Unit byte
+
+const (
+	//UnitPoint пункт, 1/72 дюйма
+	UnitPoint Unit = iota
+	//UnitMM миллиметр
+	UnitMM
+	//UnitInch дюйм
+	UnitInch
+)
+
+//Пунктов в единице
+var unitPoints = []float64{1, 72 / 25.4, 72}
+
+//Размер модуля в пунктах для векторных форматов
+func (s Style) modulePoints(code *Code) float64 {
+	if s.PhysicalSize > 0 {
+		return s.PhysicalSize / float64(code.Width()+2*s.QuietZone)
+	}
+	return float64(s.ModuleSize)
+}
+
+//Renderer выводит матрицу символа в w в своем формате
+type Renderer interface {
+	Render(w io.Writer, code *Code, style Style) error
+}
+
+//RendererFunc функция как Renderer
+type RendererFunc func(w io.Writer, code *Code, style Style) error
+
+//Render вызывает f
+func (f RendererFunc) Render(w io.Writer, code *Code, style Style) error {
+	return f(w, code, style)
+}
+
+//SheetRenderer Renderer, умеющий выводить несколько символов одного размера на одном листе для AppendSheet
+type SheetRenderer interface {
+	Renderer
+	RenderSheet(w io.Writer, codes []*Code, style Style) error
+}
+
+//Зарегистрированные форматы и расширения файлов
+var (
+	renderersMu   sync.RWMutex
+	renderers     = map[Format]Renderer{}
+	renderersExts = map[string]Format{}
+)
+
+func init() {
+	RegisterRenderer(FormatPNG, imageRenderer(FormatPNG), ".png")
+	RegisterRenderer(FormatGIF, imageRenderer(FormatGIF), ".gif")
+	RegisterRenderer(FormatJPEG, imageRenderer(FormatJPEG), ".jpg", ".jpeg")
+}
+
+//RegisterRenderer регистрирует формат и расширения файлов для него, например ".svg".
+//Повторная регистрация заменяет формат, FormatAuto зарегистрировать нельзя.
+//AppendSheet доступен, только если r реализует SheetRenderer, иначе доступен только AppendFiles
+func RegisterRenderer(format Format, r Renderer, exts ...string) {
+	if format == FormatAuto || r == nil {
+		panic("goqr: register renderer with empty format or nil renderer")
+	}
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	renderers[format] = r
+	for _, ext := range exts {
+		renderersExts[strings.ToLower(ext)] = format
+	}
+}
+
+//LookupRenderer зарегистрированный Renderer формата
+func LookupRenderer(format Format) (Renderer, bool) {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	r, ok := renderers[format]
+	return r, ok
+}
+
+//FormatByExt формат по расширению имени файла
+func FormatByExt(name string) (Format, bool) {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	format, ok := renderersExts[strings.ToLower(filepath.Ext(name))]
+	return format, ok
+}
+
+//Вывод символов в w: один символ или лист символов при AppendSheet
+func (c config) render(w io.Writer, codes []*Code) error {
+	format := c.format
+	if format == FormatAuto {
+		format = FormatPNG
+		if _, ok := c.style.Logo.(*gif.GIF); ok {
+			format = FormatGIF
+		}
+	}
+	r, ok := LookupRenderer(format)
+	if !ok {
+		return fmt.Errorf("format %q not registered", format)
+	}
+	if len(codes) == 1 {
+		return r.Render(w, codes[0], c.style)
+	}
+	if sheet, ok := r.(SheetRenderer); ok && c.opt.Append == AppendSheet {
+		return sheet.RenderSheet(w, codes, c.style)
+	}
+	if c.opt.Append == AppendSheet {
+		return fmt.Errorf("append sheet not supported by format %q: renderer does not implement SheetRenderer, use AppendFiles", format)
+	}
+	return errors.New("append files needs qrPath")
+}
+
+//Вывод растровых изображений png, gif и jpeg
+type imageRenderer Format
+
+//Render рисует символ с картинкой и кодирует изображение
+func (r imageRenderer) Render(w io.Writer, code *Code, style Style) error {
+	return r.encode(w, paintCode(code, style), style)
+}
+
+//RenderSheet рисует символы одного размера на одном листе
+func (r imageRenderer) RenderSheet(w io.Writer, codes []*Code, style Style) error {
+	imgs := make([]interface{}, len(codes))
+	for i, code := range codes {
+		imgs[i] = paintCode(code, style)
+	}
+	return r.encode(w, sheetQR(imgs, style.Light), style)
+}
+
+//Рисование символа, картинка только в qr
+func paintCode(code *Code, style Style) interface{} {
+	if code.qr {
+		return paintQR(code.version, &code.modules, style)
+	}
+	return paintImage(code.Height(), 0, &code.modules, nil, style)
+}
+
+//Приведение изображения к формату и запись в w
+func (r imageRenderer) encode(w io.Writer, img interface{}, style Style) error {
+	switch img2 := r.convert(img, style).(type) {
+	case *gif.GIF:
+		return gif.EncodeAll(w, img2)
+	case image.Image:
+		if Format(r) == FormatJPEG {
+			return jpeg.Encode(w, img2, &jpeg.Options{Quality: style.Quality})
+		}
+		return png.Encode(w, tightPalette(img2))
+	}
+	return errors.New("image wrong type")
+}
+
+//Изображение с палитрой из использованных цветов, если их не больше 256.
+//Глубина png зависит от размера палитры: два цвета пишутся одним битом
+func tightPalette(img image.Image) image.Image {
+	switch p := img.(type) {
+	case *image.Paletted:
+		//Неиспользованные цвета убираются из палитры заменой индексов
+		var used [256]bool
+		for _, i := range p.Pix {
+			used[i] = true
+		}
+		var remap [256]uint8
+		var pal color.Palette
+		for i, c := range p.Palette {
+			if used[i] {
+				remap[i] = uint8(len(pal))
+				pal = append(pal, c)
+			}
+		}
+		if len(pal) == len(p.Palette) {
+			return p
+		}
+		tight := image.NewPaletted(p.Rect, pal)
+		for y := 0; y < p.Rect.Dy(); y++ {
+			row, out := p.Pix[y*p.Stride:y*p.Stride+p.Rect.Dx()], tight.Pix[y*tight.Stride:]
+			for x, i := range row {
+				out[x] = remap[i]
+			}
+		}
+		return tight
+	case *image.NRGBA:
+		//Цвета берутся прямо из Pix, карта цветов проверяется только при смене цвета
+		tight := image.NewPaletted(p.Rect, nil)
+		index := map[color.NRGBA]uint8{}
+		var last color.NRGBA
+		var lastIndex uint8
+		for y := 0; y < p.Rect.Dy(); y++ {
+			row, out := p.Pix[y*p.Stride:y*p.Stride+p.Rect.Dx()*4], tight.Pix[y*tight.Stride:]
+			for x := 0; x < len(row); x += 4 {
+				c := color.NRGBA{row[x], row[x+1], row[x+2], row[x+3]}
+				if c != last || len(tight.Palette) == 0 {
+					i, ok := index[c]
+					if !ok {
+						if len(tight.Palette) == 256 {
+							return img
+						}
+						i = uint8(len(tight.Palette))
+						index[c] = i
+						tight.Palette = append(tight.Palette, c)
+					}
+					last, lastIndex = c, i
+				}
+				out[x/4] = lastIndex
+			}
+		}
+		return tight
+	}
+	return img
+}
+
+//Приведение изображения к формату вывода: первый кадр гифки для png и jpeg, картинка в гифку для gif
+func (r imageRenderer) convert(img interface{}, style Style) interface{} {
+	switch img2 := img.(type) {
+	case *gif.GIF:
+		if Format(r) != FormatGIF {
+			return img2.Image[0]
+		}
+	case image.Image:
+		if Format(r) == FormatGIF {
+			if frame, ok := img2.(*image.Paletted); ok {
+				return &gif.GIF{Image: []*image.Paletted{frame}, Delay: []int{0}}
+			}
+			//Без картинки хватает двух цветов
+			if style.Logo == nil {
+				frame := image.NewPaletted(img2.Bounds(), color.Palette{style.Light, style.Dark})
+				draw.Draw(frame, frame.Rect, img2, img2.Bounds().Min, draw.Src)
+				return &gif.GIF{Image: []*image.Paletted{frame}, Delay: []int{0}}
+			}
+			frame := image.NewPaletted(img2.Bounds(), palette.Plan9)
+			draw.FloydSteinberg.Draw(frame, frame.Rect, img2, img2.Bounds().Min)
+			return &gif.GIF{Image: []*image.Paletted{frame}, Delay: []int{0}}
+		}
+	}
+	return img
+}
+
+//Область картинки qr в модулях от края тихой зоны, пустая без картинки
+func logoArea(code *Code, style Style) image.Rectangle {
+	if !code.qr || style.Logo == nil {
+		return image.Rectangle{}
+	}
+	size := logoModules(code.version, style.LogoRatio)
+	if size < 1 {
+		return image.Rectangle{}
+	}
+	x, y := (code.Width()+2*style.QuietZone-size)/2, (code.Height()+2*style.QuietZone-size)/2
+	return image.Rect(x, y, x+size, y+size)
+}
+
+//Темные модули вне картинки, объединенные в прямоугольники, в модулях от края тихой зоны
+func darkRects(code *Code, style Style) []image.Rectangle {
+	logo := logoArea(code, style)
+	dark := func(x, y int) bool {
+		return code.Module(x, y) && !image.Pt(x+style.QuietZone, y+style.QuietZone).In(logo)
+	}
+	rects := mergeModules(code.Width(), code.Height(), dark)
+	for i := range rects {
+		rects[i] = rects[i].Add(image.Pt(style.QuietZone, style.QuietZone))
+	}
+	return rects
+}
+
+//Объединение темных модулей в прямоугольники: отрезки строк, продолженные вниз одинаковыми отрезками
+func mergeModules(width, height int, dark func(x, y int) bool) []image.Rectangle {
+	used := make([][]bool, height)
+	for y := range used {
+		used[y] = make([]bool, width)
+	}
+	free := func(x, y int) bool {
+		return dark(x, y) && !used[y][x]
+	}
+	var rects []image.Rectangle
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !free(x, y) {
+				continue
+			}
+			x1 := x
+			for x1 < width && free(x1, y) {
+				x1++
+			}
+			//Продолжение вниз, пока строка ниже содержит ровно такой же отрезок
+			y1 := y + 1
+			for ; y1 < height; y1++ {
+				same := (x == 0 || !free(x-1, y1)) && (x1 == width || !free(x1, y1))
+				for i := x; i < x1 && same; i++ {
+					same = free(i, y1)
+				}
+				if !same {
+					break
+				}
+			}
+			for j := y; j < y1; j++ {
+				for i := x; i < x1; i++ {
+					used[j][i] = true
+				}
+			}
+			rects = append(rects, image.Rect(x, y, x1, y1))
+			x = x1
+		}
+	}
+	return rects
+}
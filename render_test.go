@@ -0,0 +1,105 @@
+package goqr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//Регистрация формата на время теста
+func registerTestRenderer(t *testing.T, format Format, r Renderer, exts ...string) {
+	RegisterRenderer(format, r, exts...)
+	t.Cleanup(func() {
+		renderersMu.Lock()
+		defer renderersMu.Unlock()
+		delete(renderers, format)
+		for _, ext := range exts {
+			delete(renderersExts, strings.ToLower(ext))
+		}
+	})
+}
+
+//Пользовательский формат доступен по имени и по расширению без учета регистра
+func TestRegisterRenderer(t *testing.T) {
+	const format Format = "size"
+	registerTestRenderer(t, format, RendererFunc(func(w io.Writer, code *Code, style Style) error {
+		_, err := fmt.Fprintf(w, "%dx%d+%d", code.Width(), code.Height(), style.QuietZone)
+		return err
+	}), ".Size")
+
+	if _, ok := LookupRenderer(format); !ok {
+		t.Fatal("renderer not registered")
+	}
+	if got, ok := FormatByExt("qr.SIZE"); !ok || got != format {
+		t.Errorf("FormatByExt = %q %v, want %q true", got, ok, format)
+	}
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, "0123456789", WithFormat(format), WithQuietZone(2)); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "21x21+2" {
+		t.Errorf("rendered %q, want %q", buf.String(), "21x21+2")
+	}
+
+	path := filepath.Join(t.TempDir(), "qr.size")
+	if err := GenerateFile("0123456789", path); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "21x21+4" {
+		t.Errorf("file %q, want %q", data, "21x21+4")
+	}
+}
+
+//Формат без SheetRenderer выводит один символ, AppendSheet для него возвращает ошибку, AppendFiles пишет файлы
+func TestRegisterRendererSheet(t *testing.T) {
+	const format Format = "version"
+	registerTestRenderer(t, format, RendererFunc(func(w io.Writer, code *Code, style Style) error {
+		_, err := fmt.Fprint(w, code.Version())
+		return err
+	}), ".version")
+
+	content := strings.Repeat("HELLO WORLD 0123456789 qr-code ", 3)
+	var buf bytes.Buffer
+	err := Generate(&buf, content, WithFormat(format), WithAppend(AppendSheet), WithMaxVersion(2))
+	if err == nil || !strings.Contains(err.Error(), "SheetRenderer") {
+		t.Errorf("append sheet: err %v, want SheetRenderer error", err)
+	}
+	if err := Generate(&buf, content, WithFormat(format), WithAppend(AppendFiles), WithMaxVersion(2)); err == nil {
+		t.Error("append files without qrPath: want error")
+	}
+	if err := Generate(&buf, "0123456789", WithFormat(format), WithAppend(AppendSheet)); err != nil {
+		t.Errorf("single code: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := GenerateFile(content, filepath.Join(dir, "qr.version"), WithAppend(AppendFiles), WithMaxVersion(2)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 4; i++ {
+		if data, _ := os.ReadFile(filepath.Join(dir, fmt.Sprintf("qr-%d.version", i))); string(data) != "2" {
+			t.Errorf("file %d: %q, want %q", i, data, "2")
+		}
+	}
+}
+
+//Пустой формат и nil Renderer не регистрируются
+func TestRegisterRendererPanic(t *testing.T) {
+	for name, register := range map[string]func(){
+		"auto": func() { RegisterRenderer(FormatAuto, imageRenderer(FormatPNG)) },
+		"nil":  func() { RegisterRenderer("nil", nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want panic", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
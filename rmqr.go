@@ -0,0 +1,284 @@
+package goqr
+
+//Маски информации о формате rmqr со стороны поискового узора и со стороны вспомогательного
+const (
+	maskRMQRFinder = 0x1fab2
+	maskRMQRSub    = 0x20a7b
+)
+
+//Высота и ширина rmqr по версии R7x43–R17x139
+var rmqrHeight = []int{
+	7, 7, 7, 7, 7,
+	9, 9, 9, 9, 9,
+	11, 11, 11, 11, 11, 11,
+	13, 13, 13, 13, 13, 13,
+	15, 15, 15, 15, 15,
+	17, 17, 17, 17, 17,
+}
+var rmqrWidth = []int{
+	43, 59, 77, 99, 139,
+	43, 59, 77, 99, 139,
+	27, 43, 59, 77, 99, 139,
+	27, 43, 59, 77, 99, 139,
+	43, 59, 77, 99, 139,
+	43, 59, 77, 99, 139,
+}
+
+//Центры выравнивающих узоров rmqr по ширине
+var rmqrAnchor = map[int][]int{
+	27:  {},
+	43:  {21},
+	59:  {19, 39},
+	77:  {25, 51},
+	99:  {23, 49, 75},
+	139: {27, 55, 83, 111},
+}
+
+var maxDataRM = []int{
+	48, 96, 160, 224, 352,
+	96, 168, 248, 336, 504,
+	56, 152, 248, 344, 456, 672,
+	96, 216, 304, 424, 584, 848,
+	264, 384, 536, 704, 1016,
+	312, 448, 624, 800, 1216,
+}
+var maxDataRH = []int{
+	24, 56, 80, 112, 192,
+	56, 88, 136, 176, 264,
+	40, 88, 120, 184, 232, 336,
+	56, 104, 160, 232, 280, 432,
+	120, 208, 248, 384, 552,
+	168, 224, 304, 448, 608,
+}
+
+var blocksRM = []int{
+	1, 1, 1, 1, 1,
+	1, 1, 1, 1, 2,
+	1, 1, 1, 1, 2, 2,
+	1, 1, 1, 2, 2, 3,
+	1, 1, 2, 2, 3,
+	1, 2, 2, 3, 4,
+}
+var blocksRH = []int{
+	1, 1, 1, 1, 2,
+	1, 1, 2, 2, 3,
+	1, 1, 2, 2, 2, 3,
+	1, 1, 2, 2, 3, 4,
+	2, 2, 3, 4, 5,
+	2, 2, 3, 4, 6,
+}
+
+var byteCorectRM = []int{
+	7, 9, 12, 16, 24,
+	9, 12, 18, 24, 18,
+	8, 12, 16, 24, 16, 24,
+	9, 14, 22, 16, 20, 20,
+	18, 26, 18, 24, 24,
+	22, 16, 22, 20, 20,
+}
+var byteCorectRH = []int{
+	10, 14, 22, 30, 22,
+	14, 22, 16, 22, 22,
+	10, 20, 16, 22, 30, 30,
+	14, 28, 20, 28, 26, 28,
+	18, 24, 24, 22, 26,
+	20, 30, 28, 26, 26,
+}
+
+//Таблицы rmqr для уровней M и H, бит уровня в информации о формате
+var rmqrLevelTables = map[Level]levelTable{
+	LevelM: {&maxDataRM, &blocksRM, &byteCorectRM, 0},
+	LevelH: {&maxDataRH, &blocksRH, &byteCorectRH, 1},
+}
+
+//Длина счетчика символов rmqr по версии
+var lenCountRMQR = map[int][]int{
+	modeNumeric: {
+		4, 5, 6, 7, 7,
+		5, 6, 7, 7, 8,
+		4, 6, 7, 7, 8, 8,
+		5, 6, 7, 7, 8, 8,
+		7, 7, 8, 8, 9,
+		7, 8, 8, 8, 9,
+	},
+	modeAlphanum: {
+		3, 5, 5, 6, 6,
+		5, 5, 6, 6, 7,
+		4, 5, 6, 6, 7, 7,
+		5, 6, 6, 7, 7, 8,
+		6, 7, 7, 7, 8,
+		6, 7, 7, 8, 8,
+	},
+	modeByte: {
+		3, 4, 5, 5, 6,
+		4, 5, 5, 6, 6,
+		3, 5, 5, 6, 6, 7,
+		4, 5, 6, 6, 7, 7,
+		6, 6, 7, 7, 7,
+		6, 6, 7, 7, 8,
+	},
+	modeKanji: {
+		2, 3, 4, 5, 5,
+		3, 4, 5, 5, 6,
+		2, 4, 5, 5, 6, 6,
+		3, 5, 5, 6, 6, 7,
+		5, 5, 6, 6, 7,
+		5, 6, 6, 6, 7,
+	},
+}
+
+//Индикаторы режимов rmqr
+var codeModeRMQR = map[int]int{modeNumeric: 1, modeAlphanum: 2, modeByte: 3, modeKanji: 4}
+
+//Маска rmqr единственная и совпадает с маской qr (y / 2 + x / 3) mod 2 = 0
+const maskRMQR = 4
+
+//EncodeRMQR кодирует строку в матрицу rmqr от R7x43 до R17x139 без вывода изображения.
+//height ограничивает высоту символа 7–17, 0 выбирает символ наименьшей площади.
+//Доступны уровни LevelM и LevelH, LevelAuto означает LevelM
+func EncodeRMQR(content string, height int, opt Options) (*Code, error) {
+	if _, err := newConfig(append(opt.options(), WithRMQR(height))); err != nil {
+		return nil, err
+	}
+	if opt.Level == LevelAuto {
+		opt.Level = LevelM
+	}
+	table := rmqrLevelTables[opt.Level]
+
+	//Выбор версии и разбиение строки на сегменты
+	version, segments, _, err := howToRMQR(content, height, table.maxData)
+	if err != nil {
+		return nil, err
+	}
+	dataImg := buildRMQR(segments, version, table)
+	return &Code{modules: dataImg, version: version, level: opt.Level, mask: Mask0 + maskRMQR}, nil
+}
+
+//Заголовки сегментов rmqr для версии
+func rmqrHeader(version int) symbolHeader {
+	head := symbolHeader{lenMode: 3, lenCount: map[int]int{}, codeMode: codeModeRMQR, lenTerminator: 3}
+	for mode, lens := range lenCountRMQR {
+		head.lenCount[mode] = lens[version]
+	}
+	return head
+}
+
+//Выбор версии rmqr наименьшей площади с заданной высотой
+func howToRMQR(content string, height int, maxData *[]int) (version int, segments []segment, length int, err error) {
+	version = -1
+	for i := range rmqrHeight {
+		if height != 0 && rmqrHeight[i] != height {
+			continue
+		}
+		if version >= 0 && rmqrHeight[i]*rmqrWidth[i] >= rmqrHeight[version]*rmqrWidth[version] {
+			continue
+		}
+		s, l := splitCharset(content, rmqrHeader(i), Options{})
+		if s != nil && l <= (*maxData)[i] {
+			version, segments, length = i, s, l
+		}
+	}
+	if version < 0 {
+		return 0, nil, 0, errOversize
+	}
+	return version, segments, length, nil
+}
+
+//Построение модулей rmqr по сегментам
+func buildRMQR(segments []segment, version int, table levelTable) [][]byte {
+	data := buildCodewords(segments, rmqrHeader(version), version, table)
+
+	//Рисование
+	height, width := rmqrHeight[version], rmqrWidth[version]
+	dataImg := make([][]byte, height)
+	for i := range dataImg {
+		dataImg[i] = make([]byte, width)
+	}
+	finder(&dataImg, 0, 0)
+	anchorPoint(&dataImg, height-3, width-3, search0, search1)
+	rmqrCorner(&dataImg)
+	rmqrAnchorPoint(&dataImg)
+	rmqrSyncLine(&dataImg)
+	rmqrInfo(&dataImg, table.levelBits, version)
+	writeBits(&dataImg, data, width-1)
+	applyMask(&dataImg, maskRMQR)
+	return dataImg
+}
+
+//Рисование угловых узоров rmqr справа сверху и слева снизу
+func rmqrCorner(img *[][]byte) {
+	height, width := len(*img), len((*img)[0])
+	(*img)[0][width-1] = search1
+	(*img)[0][width-2] = search1
+	(*img)[1][width-1] = search1
+	(*img)[1][width-2] = search0
+	for x := 0; x < 3; x++ {
+		(*img)[height-1][x] = search1
+	}
+	if height > 9 {
+		(*img)[height-2][0] = search1
+		(*img)[height-2][1] = search0
+	}
+}
+
+//Рисование выравнивающих узоров rmqr 3x3 по верхнему и нижнему краю
+func rmqrAnchorPoint(img *[][]byte) {
+	height, width := len(*img), len((*img)[0])
+	for _, x := range rmqrAnchor[width] {
+		for i := 0; i < 3; i++ {
+			for j := -1; j < 2; j++ {
+				k := anchor1
+				if i == 1 && j == 0 {
+					k = anchor0
+				}
+				(*img)[i][x+j] = k
+				(*img)[height-1-i][x+j] = k
+			}
+		}
+	}
+}
+
+//Рисование полос синхронизации rmqr по краям и через центры выравнивающих узоров
+func rmqrSyncLine(img *[][]byte) {
+	height, width := len(*img), len((*img)[0])
+	sync := func(y, x, i int) {
+		if (*img)[y][x] != 0 {
+			return
+		}
+		if i%2 == 0 {
+			(*img)[y][x] = sync1
+		} else {
+			(*img)[y][x] = sync0
+		}
+	}
+	for x := 0; x < width; x++ {
+		sync(0, x, x)
+		sync(height-1, x, x)
+	}
+	columns := append([]int{0, width - 1}, rmqrAnchor[width]...)
+	for y := 0; y < height; y++ {
+		for _, x := range columns {
+			sync(y, x, y)
+		}
+	}
+}
+
+//Рисование информации о формате rmqr возле поискового и вспомогательного узоров
+func rmqrInfo(img *[][]byte, levelBits, version int) {
+	height, width := len(*img), len((*img)[0])
+	code := versionCode(levelBits<<5 | version)
+	bit := func(code, i int) byte {
+		if code&(1<<i) != 0 {
+			return mask1
+		}
+		return mask0
+	}
+	for i := 0; i < 18; i++ {
+		(*img)[1+i%5][8+i/5] = bit(code^maskRMQRFinder, i)
+		if i < 15 {
+			(*img)[height-6+i%5][width-8+i/5] = bit(code^maskRMQRSub, i)
+		} else {
+			(*img)[height-6][width-20+i] = bit(code^maskRMQRSub, i)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package goqr
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+//R7x43-M с шестью цифрами: поисковый, вспомогательный и угловые узоры, формат с двух сторон
+func TestEncodeRMQRGolden(t *testing.T) {
+	code, err := EncodeRMQR("123456", 7, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.Version() != 1 || code.Level() != LevelM || code.Mask() != Mask4 {
+		t.Fatalf("version %d level %d mask %d, want 1 %d %d", code.Version(), code.Level(), code.Mask(), LevelM, Mask4)
+	}
+	checkRows(t, code, []string{
+		"#######.#.#.#.#.#.#.###.#.#.#.#.#.#.#.#.###",
+		"#.....#..#.#.....#..#.##....##..##.##...#.#",
+		"#.###.#.#.###...#######.##...##.#.#########",
+		"#.###.#..##...#..#.##.###..#######....#...#",
+		"#.###.#...#.#..####.###...#...###..#..#.#.#",
+		"#.....#.####...###.##.######..#.#####.#...#",
+		"#######.#.#.#.#.#.#.###.#.#.#.#.#.#.#.#####",
+	})
+}
+
+//Символ наименьшей площади для заданной высоты
+func TestEncodeRMQRHeight(t *testing.T) {
+	for _, tt := range []struct {
+		height, width, wantHeight int
+	}{
+		{0, 27, 11},
+		{7, 43, 7},
+		{11, 27, 11},
+		{17, 43, 17},
+	} {
+		code, err := EncodeRMQR("123456", tt.height, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code.Width() != tt.width || code.Height() != tt.wantHeight {
+			t.Errorf("height %d: R%dx%d, want R%dx%d", tt.height, code.Height(), code.Width(), tt.wantHeight, tt.width)
+		}
+	}
+}
+
+//Generate с WithRMQR рисует прямоугольный символ и отклоняет четную высоту
+func TestGenerateRMQR(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Generate(&buf, "123456", WithRMQR(7), WithQuietZone(2)); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 43+2*2 || b.Dy() != 7+2*2 {
+		t.Errorf("image %dx%d, want %dx%d", b.Dx(), b.Dy(), 43+2*2, 7+2*2)
+	}
+	if err := Generate(&buf, "123456", WithRMQR(8)); err == nil {
+		t.Error("height 8: want error")
+	}
+}
@@ -0,0 +1,76 @@
+package goqr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+)
+
+//FormatSVG svg, темные модули объединяются в один путь
+const FormatSVG Format = "svg"
+
+func init() {
+	RegisterRenderer(FormatSVG, RendererFunc(renderSVG), ".svg")
+}
+
+//Вывод символа в svg: фон, путь из прямоугольников темных модулей и картинка в data uri
+func renderSVG(w io.Writer, code *Code, style Style) error {
+	width, height := code.Width()+2*style.QuietZone, code.Height()+2*style.QuietZone
+	logo, err := svgLogo(code, style)
+	if err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" xmlns:xlink=\"http://www.w3.org/1999/xlink\" version=\"1.1\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" shape-rendering=\"crispEdges\">\n",
+		width*style.ModuleSize, height*style.ModuleSize, width, height)
+	fmt.Fprintf(buf, "<rect width=\"%d\" height=\"%d\"%s/>\n", width, height, svgFill(style.Light))
+	fmt.Fprintf(buf, "<path%s d=\"", svgFill(style.Dark))
+	for _, r := range darkRects(code, style) {
+		fmt.Fprintf(buf, "M%d %dh%dv%dh-%dz", r.Min.X, r.Min.Y, r.Dx(), r.Dy(), r.Dx())
+	}
+	fmt.Fprintf(buf, "\"/>\n")
+	if area := logoArea(code, style); logo != "" {
+		fmt.Fprintf(buf, "<image x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" xlink:href=\"%s\"/>\n", area.Min.X, area.Min.Y, area.Dx(), area.Dy(), logo)
+	}
+	fmt.Fprintf(buf, "</svg>\n")
+	return buf.Flush()
+}
+
+//Картинка qr в data uri, пустая строка без картинки
+func svgLogo(code *Code, style Style) (string, error) {
+	if logoArea(code, style).Empty() {
+		return "", nil
+	}
+	var data bytes.Buffer
+	mime := "image/png"
+	switch logo := style.Logo.(type) {
+	case *gif.GIF:
+		mime = "image/gif"
+		if err := gif.EncodeAll(&data, logo); err != nil {
+			return "", err
+		}
+	case image.Image:
+		if err := png.Encode(&data, logo); err != nil {
+			return "", err
+		}
+	}
+	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(data.Bytes()), nil
+}
+
+//Атрибут заливки цветом с прозрачностью
+func svgFill(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	fill := fmt.Sprintf(" fill=\"#%02x%02x%02x\"", n.R, n.G, n.B)
+	if n.A != 0xff {
+		fill += fmt.Sprintf(" fill-opacity=\"%.3g\"", float64(n.A)/0xff)
+	}
+	return fill
+}
@@ -0,0 +1,116 @@
+package goqr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var svgRect = regexp.MustCompile(`M(\d+) (\d+)h(\d+)v(\d+)h-(\d+)z`)
+
+//Путь svg из объединенных прямоугольников покрывает ровно темные модули со сдвигом на тихую зону
+func TestRenderSVG(t *testing.T) {
+	code, err := Encode("HELLO WORLD 0123456789", Options{Level: LevelM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	style := defaultConfig().style
+	style.QuietZone, style.ModuleSize = 3, 5
+	var buf bytes.Buffer
+	if err := renderSVG(&buf, code, style); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	side := code.Width() + 2*style.QuietZone
+	if head := fmt.Sprintf(`width="%d" height="%d" viewBox="0 0 %d %d"`, side*5, side*5, side, side); !strings.Contains(svg, head) {
+		t.Errorf("svg without %s", head)
+	}
+
+	dark := 0
+	for y := 0; y < code.Height(); y++ {
+		for x := 0; x < code.Width(); x++ {
+			if code.Module(x, y) {
+				dark++
+			}
+		}
+	}
+	rects := svgRect.FindAllStringSubmatch(svg, -1)
+	if len(rects) == 0 || len(rects) >= dark {
+		t.Fatalf("subpaths %d, want fewer than %d dark modules", len(rects), dark)
+	}
+	covered := map[image.Point]bool{}
+	for _, r := range rects {
+		n := make([]int, 5)
+		for i := range n {
+			n[i], _ = strconv.Atoi(r[i+1])
+		}
+		if n[2] != n[4] {
+			t.Fatalf("subpath %s not closed", r[0])
+		}
+		for y := n[1]; y < n[1]+n[3]; y++ {
+			for x := n[0]; x < n[0]+n[2]; x++ {
+				p := image.Pt(x-style.QuietZone, y-style.QuietZone)
+				if covered[p] || !code.Module(p.X, p.Y) {
+					t.Fatalf("module %v covered twice or light", p)
+				}
+				covered[p] = true
+			}
+		}
+	}
+	if len(covered) != dark {
+		t.Errorf("covered %d modules, want %d", len(covered), dark)
+	}
+	if strings.Contains(svg, "<image") {
+		t.Error("image without logo")
+	}
+}
+
+//Картинка встраивается в svg как png в data uri по центру символа
+func TestRenderSVGLogo(t *testing.T) {
+	code, err := Encode("HELLO WORLD 0123456789", Options{Level: LevelH, MinVersion: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logo := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	logo.Set(1, 2, color.NRGBA{255, 0, 0, 255})
+	style := defaultConfig().style
+	style.Logo = logo
+	var buf bytes.Buffer
+	if err := renderSVG(&buf, code, style); err != nil {
+		t.Fatal(err)
+	}
+	area := logoArea(code, style)
+	prefix := fmt.Sprintf(`<image x="%d" y="%d" width="%d" height="%d" xlink:href="data:image/png;base64,`, area.Min.X, area.Min.Y, area.Dx(), area.Dy())
+	svg := buf.String()
+	i := strings.Index(svg, prefix)
+	if area.Empty() || i < 0 {
+		t.Fatalf("svg without %s", prefix)
+	}
+	data := svg[i+len(prefix):]
+	data = data[:strings.IndexByte(data, '"')]
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, a := img.At(1, 2).RGBA(); r != 0xffff || a != 0xffff || img.Bounds() != logo.Bounds() {
+		t.Error("logo in data uri differs")
+	}
+	for _, r := range svgRect.FindAllStringSubmatch(svg, -1) {
+		x, _ := strconv.Atoi(r[1])
+		y, _ := strconv.Atoi(r[2])
+		if image.Pt(x, y).In(area) {
+			t.Fatalf("subpath %s under logo", r[0])
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package goqr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+)
+
+const (
+	//FormatSixel изображение с картинкой в виде потока sixel
+	FormatSixel Format = "sixel"
+	//FormatKitty изображение с картинкой в escape-последовательностях графического протокола kitty
+	FormatKitty Format = "kitty"
+)
+
+func init() {
+	RegisterRenderer(FormatSixel, RendererFunc(renderSixel), ".six", ".sixel")
+	RegisterRenderer(FormatKitty, RendererFunc(renderKitty))
+}
+
+//Длина части base64 в одной escape-последовательности kitty
+const kittyChunk = 4096
+
+//Изображение символа с картинкой, из гифки берется первый кадр
+func paintFrame(code *Code, style Style) image.Image {
+	switch img := paintCode(code, style).(type) {
+	case *gif.GIF:
+		return img.Image[0]
+	case image.Image:
+		return img
+	}
+	return nil
+}
+
+//Вывод изображения символа потоком sixel с палитрой гифки
+func renderSixel(w io.Writer, code *Code, style Style) error {
+	frame := imageRenderer(FormatGIF).convert(paintFrame(code, style), style).(*gif.GIF).Image[0]
+	b := frame.Bounds()
+	width, height := b.Dx(), b.Dy()
+
+	buf := bufio.NewWriter(w)
+	//Начало, соотношение сторон 1:1 и размер
+	fmt.Fprintf(buf, "\x1bPq\"1;1;%d;%d", width, height)
+	for i, c := range frame.Palette {
+		n := color.NRGBAModel.Convert(c).(color.NRGBA)
+		fmt.Fprintf(buf, "#%d;2;%d;%d;%d", i, int(n.R)*100/0xff, int(n.G)*100/0xff, int(n.B)*100/0xff)
+	}
+
+	//Полосы по шесть строк, в каждой полосе проход на каждый цвет
+	row := make([]byte, width)
+	for band := 0; band < height; band += 6 {
+		used := make([]bool, len(frame.Palette))
+		for y := band; y < band+6 && y < height; y++ {
+			for x := 0; x < width; x++ {
+				used[frame.ColorIndexAt(b.Min.X+x, b.Min.Y+y)] = true
+			}
+		}
+		first := true
+		for i := range used {
+			if !used[i] {
+				continue
+			}
+			for x := range row {
+				var bits byte
+				for k := 0; k < 6 && band+k < height; k++ {
+					if int(frame.ColorIndexAt(b.Min.X+x, b.Min.Y+band+k)) == i {
+						bits |= 1 << k
+					}
+				}
+				row[x] = '?' + bits
+			}
+			if !first {
+				buf.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(buf, "#%d", i)
+			writeSixelRow(buf, row)
+		}
+		buf.WriteByte('-')
+	}
+	buf.WriteString("\x1b\\")
+	return buf.Flush()
+}
+
+//Запись строки sixel со сжатием повторов !n
+func writeSixelRow(buf *bufio.Writer, row []byte) {
+	for x := 0; x < len(row); {
+		n := 1
+		for x+n < len(row) && row[x+n] == row[x] {
+			n++
+		}
+		if n > 3 {
+			fmt.Fprintf(buf, "!%d%c", n, row[x])
+		} else {
+			for k := 0; k < n; k++ {
+				buf.WriteByte(row[x])
+			}
+		}
+		x += n
+	}
+}
+
+//Вывод изображения символа в png частями по протоколу kitty
+func renderKitty(w io.Writer, code *Code, style Style) error {
+	var data bytes.Buffer
+	if err := png.Encode(&data, tightPalette(paintFrame(code, style))); err != nil {
+		return err
+	}
+	payload := base64.StdEncoding.EncodeToString(data.Bytes())
+
+	buf := bufio.NewWriter(w)
+	for i := 0; i < len(payload); i += kittyChunk {
+		end, more := i+kittyChunk, 1
+		if end >= len(payload) {
+			end, more = len(payload), 0
+		}
+		//Формат png и вывод на экран задаются в первой части
+		if i == 0 {
+			fmt.Fprintf(buf, "\x1b_Ga=T,f=100,m=%d;%s\x1b\\", more, payload[i:end])
+		} else {
+			fmt.Fprintf(buf, "\x1b_Gm=%d;%s\x1b\\", more, payload[i:end])
+		}
+	}
+	buf.WriteByte('\n')
+	return buf.Flush()
+}
@@ -0,0 +1,182 @@
+package goqr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//Символ с красной картинкой
+func terminalCode(t *testing.T) (*Code, Style) {
+	t.Helper()
+	code, err := Encode("HELLO WORLD 0123456789", Options{Level: LevelH, MinVersion: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logo := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for i := 0; i < len(logo.Pix); i += 4 {
+		logo.Pix[i], logo.Pix[i+3] = 0xff, 0xff
+	}
+	style := defaultConfig().style
+	style.Logo, style.ModuleSize = logo, 2
+	return code, style
+}
+
+//Разбор потока sixel в изображение с палитрой
+func decodeSixel(t *testing.T, data string) *image.Paletted {
+	t.Helper()
+	m := regexp.MustCompile(`^\x1bPq"1;1;(\d+);(\d+)`).FindStringSubmatch(data)
+	if m == nil || !strings.HasSuffix(data, "\x1b\\") {
+		t.Fatalf("sixel framing wrong: %.20q ... %q", data, data[len(data)-2:])
+	}
+	width, _ := strconv.Atoi(m[1])
+	height, _ := strconv.Atoi(m[2])
+	img := image.NewPaletted(image.Rect(0, 0, width, height), nil)
+	body := data[len(m[0]) : len(data)-2]
+
+	number := func(i int) (int, int) {
+		j := i
+		for j < len(body) && body[j] >= '0' && body[j] <= '9' {
+			j++
+		}
+		n, _ := strconv.Atoi(body[i:j])
+		return n, j
+	}
+	x, band, index := 0, 0, 0
+	for i := 0; i < len(body); {
+		switch c := body[i]; {
+		case c == '#':
+			index, i = number(i + 1)
+			//Определение цвета #i;2;r;g;b
+			if i < len(body) && body[i] == ';' {
+				var rgb [4]int
+				for k := range rgb {
+					rgb[k], i = number(i + 1)
+				}
+				for len(img.Palette) <= index {
+					img.Palette = append(img.Palette, nil)
+				}
+				img.Palette[index] = color.RGBA{uint8(rgb[1] * 0xff / 100), uint8(rgb[2] * 0xff / 100), uint8(rgb[3] * 0xff / 100), 0xff}
+			}
+		case c == '$':
+			x, i = 0, i+1
+		case c == '-':
+			x, band, i = 0, band+6, i+1
+		case c == '!' || c >= '?' && c <= '~':
+			n := 1
+			if c == '!' {
+				n, i = number(i + 1)
+			}
+			bits := body[i] - '?'
+			for ; n > 0; n-- {
+				for k := 0; k < 6; k++ {
+					if bits&(1<<k) != 0 {
+						img.SetColorIndex(x, band+k, uint8(index))
+					}
+				}
+				x++
+			}
+			i++
+		default:
+			t.Fatalf("sixel symbol %q at %d", c, i)
+		}
+	}
+	return img
+}
+
+//Поток sixel в рамке ESC P q … ESC \ повторяет изображение с картинкой
+func TestRenderSixel(t *testing.T) {
+	code, style := terminalCode(t)
+	var buf bytes.Buffer
+	if err := renderSixel(&buf, code, style); err != nil {
+		t.Fatal(err)
+	}
+	img := decodeSixel(t, buf.String())
+	want := paintFrame(code, style)
+	if img.Bounds() != want.Bounds() {
+		t.Fatalf("sixel %v, want %v", img.Bounds(), want.Bounds())
+	}
+	red := 0
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			wr, wg, wb, _ := want.At(x, y).RGBA()
+			if r>>8 != wr>>8 || g>>8 != wg>>8 || b>>8 != wb>>8 {
+				t.Fatalf("pixel %d,%d: %v, want %v", x, y, img.At(x, y), want.At(x, y))
+			}
+			if r>>8 == 0xff && g == 0 && b == 0 {
+				red++
+			}
+		}
+	}
+	if logo := style.Logo.(image.Image).Bounds(); red < logo.Dx()*logo.Dy() {
+		t.Errorf("red pixels %d, want at least %d of logo", red, logo.Dx()*logo.Dy())
+	}
+}
+
+var kittyPart = regexp.MustCompile(`\x1b_G([^;]*);([^\x1b]*)\x1b\\`)
+
+//Png в частях base64 по 4096 знаков: m=1 у всех частей кроме последней
+func TestRenderKitty(t *testing.T) {
+	code, style := terminalCode(t)
+	//Шум в картинке увеличивает png больше одной части
+	logo := style.Logo.(*image.NRGBA)
+	for i := 0; i < len(logo.Pix); i += 4 {
+		logo.Pix[i+1], logo.Pix[i+2] = uint8(i*37), uint8(i*91)
+	}
+	style.ModuleSize = 8
+	var buf bytes.Buffer
+	if err := renderKitty(&buf, code, style); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasSuffix(out, "\x1b\\\n") {
+		t.Error("kitty output without final newline")
+	}
+	parts := kittyPart.FindAllStringSubmatch(out, -1)
+	if len(parts) < 2 {
+		t.Fatalf("parts %d, want at least 2", len(parts))
+	}
+	var payload strings.Builder
+	for i, p := range parts {
+		keys, chunk := p[1], p[2]
+		last := i == len(parts)-1
+		switch {
+		case i == 0 && keys != "a=T,f=100,m=1":
+			t.Errorf("part 0 keys %q, want a=T,f=100,m=1", keys)
+		case i > 0 && !last && keys != "m=1":
+			t.Errorf("part %d keys %q, want m=1", i, keys)
+		case last && keys != "m=0":
+			t.Errorf("last part keys %q, want m=0", keys)
+		}
+		if !last && len(chunk) != kittyChunk || last && (len(chunk) == 0 || len(chunk) > kittyChunk) {
+			t.Errorf("part %d length %d, want %d", i, len(chunk), kittyChunk)
+		}
+		payload.WriteString(chunk)
+	}
+	data, err := base64.StdEncoding.DecodeString(payload.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := paintFrame(code, style)
+	if img.Bounds() != want.Bounds() {
+		t.Fatalf("png %v, want %v", img.Bounds(), want.Bounds())
+	}
+	center := img.Bounds().Max.Div(2)
+	if got, exp := color.NRGBAModel.Convert(img.At(center.X, center.Y)), color.NRGBAModel.Convert(want.At(center.X, center.Y)); got != exp {
+		t.Errorf("center %v, want logo %v", got, exp)
+	}
+	if r, g, b, _ := img.At(center.X, center.Y).RGBA(); r>>8 != 0xff || r == g && g == b {
+		t.Errorf("center %v is not logo", img.At(center.X, center.Y))
+	}
+}
@@ -0,0 +1,102 @@
+package goqr
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+)
+
+const (
+	//FormatText текст из полублоков ▀▄█, в строке терминала две строки модулей
+	FormatText Format = "text"
+	//FormatANSI полублоки ▀ с цветами Style.Dark и Style.Light в 24-битных escape-последовательностях ANSI
+	FormatANSI Format = "ansi"
+	//FormatASCII текст из ## и пробелов для терминалов без юникода, строка модулей в строке терминала
+	FormatASCII Format = "ascii"
+)
+
+func init() {
+	RegisterRenderer(FormatText, RendererFunc(renderText), ".txt")
+	RegisterRenderer(FormatANSI, RendererFunc(renderANSI), ".ans")
+	RegisterRenderer(FormatASCII, RendererFunc(renderASCII))
+}
+
+//Полублоки по верхнему и нижнему модулю ячейки
+var halfBlocks = [2][2]string{{" ", "▄"}, {"▀", "█"}}
+
+//Рисуется ли модуль символом: темные модули, при Style.Invert светлые. Тихая зона светлая
+func textModule(code *Code, style Style, x, y int) bool {
+	return code.Module(x, y) != style.Invert
+}
+
+//Вывод символа полублоками
+func renderText(w io.Writer, code *Code, style Style) error {
+	q := style.QuietZone
+	buf := bufio.NewWriter(w)
+	for y := -q; y < code.Height()+q; y += 2 {
+		for x := -q; x < code.Width()+q; x++ {
+			top, bottom := 0, 0
+			if textModule(code, style, x, y) {
+				top = 1
+			}
+			//Нижняя строка последней ячейки при нечетной высоте в тихой зоне
+			if y+1 < code.Height()+q && textModule(code, style, x, y+1) {
+				bottom = 1
+			}
+			buf.WriteString(halfBlocks[top][bottom])
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Flush()
+}
+
+//Вывод символа цветными полублоками: верхний модуль цветом символа, нижний цветом фона
+func renderANSI(w io.Writer, code *Code, style Style) error {
+	q := style.QuietZone
+	dark, light := ansiColor(style.Dark), ansiColor(style.Light)
+	if style.Invert {
+		dark, light = light, dark
+	}
+	module := func(x, y int) string {
+		if code.Module(x, y) {
+			return dark
+		}
+		return light
+	}
+	buf := bufio.NewWriter(w)
+	for y := -q; y < code.Height()+q; y += 2 {
+		for x := -q; x < code.Width()+q; x++ {
+			bottom := light
+			if y+1 < code.Height()+q {
+				bottom = module(x, y+1)
+			}
+			fmt.Fprintf(buf, "\x1b[38;2;%sm\x1b[48;2;%sm▀", module(x, y), bottom)
+		}
+		buf.WriteString("\x1b[0m\n")
+	}
+	return buf.Flush()
+}
+
+//Вывод символа ## и пробелами, модуль шириной в два знака для квадратных пропорций
+func renderASCII(w io.Writer, code *Code, style Style) error {
+	q := style.QuietZone
+	buf := bufio.NewWriter(w)
+	for y := -q; y < code.Height()+q; y++ {
+		for x := -q; x < code.Width()+q; x++ {
+			if textModule(code, style, x, y) {
+				buf.WriteString("##")
+			} else {
+				buf.WriteString("  ")
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Flush()
+}
+
+//Цвет в параметрах 24-битной escape-последовательности r;g;b
+func ansiColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("%d;%d;%d", n.R, n.G, n.B)
+}
@@ -0,0 +1,104 @@
+package goqr
+
+import (
+	"bytes"
+	"image/color"
+	"io"
+	"testing"
+)
+
+//Символ из строк с # для темных модулей
+func testCode(rows ...string) *Code {
+	modules := make([][]byte, len(rows))
+	for y, row := range rows {
+		modules[y] = make([]byte, len(row))
+		for x := range row {
+			modules[y][x] = search0
+			if row[x] == '#' {
+				modules[y][x] = search1
+			}
+		}
+	}
+	return &Code{modules: modules}
+}
+
+//Текстовые форматы на символе 3x3 нечетной высоты
+func TestRenderText(t *testing.T) {
+	code := testCode(
+		"#.#",
+		".#.",
+		"##.",
+	)
+	for _, tt := range []struct {
+		name   string
+		render func(w io.Writer, code *Code, style Style) error
+		style  Style
+		want   string
+	}{
+		{"text", renderText, Style{}, "" +
+			"▀▄▀\n" +
+			"▀▀ \n"},
+		//Последняя строка дополняется светлыми модулями
+		{"text quiet zone", renderText, Style{QuietZone: 1}, "" +
+			" ▄ ▄ \n" +
+			" ▄█  \n" +
+			"     \n"},
+		{"text invert", renderText, Style{Invert: true}, "" +
+			"▄▀▄\n" +
+			"  ▀\n"},
+		{"text invert quiet zone", renderText, Style{QuietZone: 1, Invert: true}, "" +
+			"█▀█▀█\n" +
+			"█▀ ██\n" +
+			"▀▀▀▀▀\n"},
+		{"ascii", renderASCII, Style{}, "" +
+			"##  ##\n" +
+			"  ##  \n" +
+			"####  \n"},
+		{"ascii quiet zone invert", renderASCII, Style{QuietZone: 1, Invert: true}, "" +
+			"##########\n" +
+			"##  ##  ##\n" +
+			"####  ####\n" +
+			"##    ####\n" +
+			"##########\n"},
+	} {
+		var buf bytes.Buffer
+		if err := tt.render(&buf, code, tt.style); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s:\n%s\nwant\n%s", tt.name, buf.String(), tt.want)
+		}
+	}
+}
+
+//Цветные полублоки: верхний модуль цветом символа, нижний цветом фона
+func TestRenderANSI(t *testing.T) {
+	code := testCode(
+		"#.",
+		".#",
+		"#.",
+	)
+	const (
+		dark  = "0;0;255"
+		light = "255;255;0"
+	)
+	cell := func(top, bottom string) string {
+		return "\x1b[38;2;" + top + "m\x1b[48;2;" + bottom + "m▀"
+	}
+	for _, tt := range []struct {
+		invert bool
+		want   string
+	}{
+		{false, cell(dark, light) + cell(light, dark) + "\x1b[0m\n" + cell(dark, light) + cell(light, light) + "\x1b[0m\n"},
+		{true, cell(light, dark) + cell(dark, light) + "\x1b[0m\n" + cell(light, dark) + cell(dark, dark) + "\x1b[0m\n"},
+	} {
+		var buf bytes.Buffer
+		style := Style{Dark: color.RGBA{0, 0, 255, 255}, Light: color.RGBA{255, 255, 0, 255}, Invert: tt.invert}
+		if err := renderANSI(&buf, code, style); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("invert %v: %q, want %q", tt.invert, buf.String(), tt.want)
+		}
+	}
+}
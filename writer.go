@@ -0,0 +1,31 @@
+package goqr
+
+import (
+	"bufio"
+	"errors"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+//LoadLogo читает картинку jpeg, png или гифку из r для WithLogo
+func LoadLogo(r io.Reader) (interface{}, error) {
+	return decodeLogo(r)
+}
+
+//Чтение картинки или гифки с определением формата по содержимому
+func decodeLogo(r io.Reader) (interface{}, error) {
+	buf := bufio.NewReader(r)
+	head, _ := buf.Peek(512)
+	switch http.DetectContentType(head) {
+	case "image/jpeg":
+		return jpeg.Decode(buf)
+	case "image/png":
+		return png.Decode(buf)
+	case "image/gif":
+		return gif.DecodeAll(buf)
+	}
+	return nil, errors.New("image wrong type")
+}